@@ -0,0 +1,164 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/localizely/localizely-client-go"
+	"github.com/spf13/viper"
+)
+
+// newApiClient returns a client for the Localizely API, or for the API URL if set (e.g. an API gateway or a local fake server).
+func newApiClient() (*localizely.APIClient, error) {
+	cfg := localizely.NewConfiguration()
+
+	httpClient, err := newHttpClient()
+	if err != nil {
+		return nil, err
+	}
+	cfg.HTTPClient = httpClient
+
+	if apiUrl := viper.GetString("api_url"); apiUrl != "" {
+		if err := validateApiUrl(apiUrl); err != nil {
+			return nil, err
+		}
+		cfg.Servers = localizely.ServerConfigurations{{URL: strings.TrimSuffix(apiUrl, "/"), Description: "Custom API URL"}}
+	}
+
+	return localizely.NewAPIClient(cfg), nil
+}
+
+func validateApiUrl(apiUrl string) error {
+	u, err := url.Parse(apiUrl)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		msg := fmt.Sprintf("The API URL '%s' is not valid.\n\nPlease set an absolute http or https URL, e.g. https://api.localizely.com\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", apiUrl)
+		return newConfigError(msg)
+	}
+
+	return nil
+}
+
+func newApiContext(ctx context.Context, apiToken string) context.Context {
+	return context.WithValue(ctx, localizely.ContextAPIKeys, map[string]localizely.APIKey{"API auth": {Key: apiToken}})
+}
+
+// ApiError is a failed request to the Localizely API.
+// It keeps the HTTP status (0 if there was no response, e.g. on network errors) and the underlying error, so the failure can be classified.
+type ApiError struct {
+	Message    string
+	StatusCode int
+	Err        error
+}
+
+func (e *ApiError) Error() string {
+	return e.Message
+}
+
+func (e *ApiError) Unwrap() error {
+	return e.Err
+}
+
+func newApiError(message string, resp *http.Response, err error) error {
+	apiErr := &ApiError{Message: message, Err: err}
+	if resp != nil {
+		apiErr.StatusCode = resp.StatusCode
+	}
+
+	return apiErr
+}
+
+// readResponseBody returns the body of the response as a string, or an empty string when there is no response (e.g. on network errors).
+func readResponseBody(resp *http.Response) string {
+	if resp == nil || resp.Body == nil {
+		return ""
+	}
+
+	b, _ := io.ReadAll(resp.Body)
+	return string(b)
+}
+
+// fetchProjectLocales returns the locale codes of all languages in the Localizely project.
+func fetchProjectLocales(ctx context.Context, apiClient *localizely.APIClient, projectId string, branch string, retryPolicy RetryPolicy) ([]string, error) {
+	var status *localizely.ProjectStatusDto
+
+	resp, err := executeWithRetry(ctx, retryPolicy, true, func() (*http.Response, error) {
+		req := apiClient.TranslationStatusAPIAPI.GetTranslationStatus(ctx, projectId)
+		if branch != "" {
+			req = req.Branch(branch)
+		}
+
+		var resp *http.Response
+		var err error
+		status, resp, err = req.Execute()
+		return resp, err
+	})
+	if err != nil {
+		return nil, newApiError(fmt.Sprintf("Failed to fetch the list of project languages from Localizely\nError: %v\n%s\n", err, readResponseBody(resp)), resp, err)
+	}
+	defer resp.Body.Close()
+
+	var localeCodes []string
+	for _, v := range status.GetLanguages() {
+		if v.GetLangCode() != "" {
+			localeCodes = append(localeCodes, v.GetLangCode())
+		}
+	}
+
+	return localeCodes, nil
+}
+
+// localProjectLocales is used instead of the project locales when the Localizely API must not be called, e.g. by the status command with the local flag.
+func localProjectLocales() ([]string, error) {
+	return nil, newConfigError("The project languages ('locales: all' or all_languages) are fetched from the Localizely API, so they cannot be used when only local changes are checked.\n\nPlease list the locale codes of the files in the config file, or check the status without the local flag.\n\n")
+}
+
+// projectLocalesFetcher returns a function that fetches the project locales on first use and caches the result.
+func projectLocalesFetcher(ctx context.Context, apiToken string, projectId string, branch string, retryPolicy RetryPolicy) func() ([]string, error) {
+	var once sync.Once
+	var localeCodes []string
+	var err error
+
+	return func() ([]string, error) {
+		once.Do(func() {
+			if err = validateApiToken(apiToken); err != nil {
+				return
+			}
+			if err = validateProjectId(projectId); err != nil {
+				return
+			}
+			var apiClient *localizely.APIClient
+			if apiClient, err = newApiClient(); err != nil {
+				return
+			}
+			localeCodes, err = fetchProjectLocales(newApiContext(ctx, apiToken), apiClient, projectId, branch, retryPolicy)
+		})
+
+		return localeCodes, err
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var checkCmd = &cobra.Command{
+	Use:     "check",
+	Short:   "Check that local localization files are up to date with Localizely",
+	Long:    fmt.Sprintf("Check that local localization files are up to date with Localizely\n\nEach download file is downloaded into memory and compared with the local file, without changing any local file.\nBy default, files are compared byte for byte. With the semantic flag, they are compared key by key for the json, flutter_arb, android_xml, ios_strings, po and pot file types.\n\nExit status:\n  0  all files are up to date\n  %d  some files are stale or missing\n  %d  the configuration is invalid\n  %d  the API token is missing or rejected\n  %d  the project was not found\n  %d  Localizely could not be reached\n  %d  Localizely rejected the request as invalid\n  %d  the check was interrupted or exceeded the timeout\n  1  the check failed for another reason", ExitCodeStale, ExitCodeConfig, ExitCodeAuth, ExitCodeNotFound, ExitCodeNetwork, ExitCodeValidation, ExitCodeCanceled),
+	Example: "  localizely-cli check --semantic",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindDownloadFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		configs, err := readPullConfigs(cmd.Context())
+		checkError(err)
+
+		semantic, err := cmd.Flags().GetBool("semantic")
+		checkError(err)
+
+		var checks []FileCheck
+		for _, config := range configs {
+			projectChecks, err := checkLocalizationFiles(cmd.Context(), config, semantic)
+			checkError(err)
+			checks = append(checks, projectChecks...)
+
+			for _, v := range projectChecks {
+				addFileReport(newFileCheckReport(config.Project, v))
+			}
+		}
+
+		var stale []FileCheck
+		for _, v := range checks {
+			if v.Stale {
+				stale = append(stale, v)
+			}
+		}
+
+		if len(stale) > 0 {
+			color.Set(color.FgRed)
+			fmt.Fprintf(os.Stderr, "Stale localization files (%d of %d):\n", len(stale), len(checks))
+			color.Unset()
+			for _, v := range stale {
+				fmt.Fprintf(os.Stderr, "  %s (%s) - %s\n", filepath.Clean(v.File.File), v.File.LocaleCode, v.Reason)
+			}
+			fmt.Fprintf(os.Stderr, "\nRun \"localizely-cli pull\" to update them.\n")
+
+			reportFailureCode("stale", fmt.Sprintf("%d of %d localization files are stale", len(stale), len(checks)))
+			os.Exit(ExitCodeStale)
+		}
+
+		reportSuccess("All %d localization files are up to date with Localizely", len(checks))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(checkCmd)
+
+	addDownloadFlags(checkCmd)
+	checkCmd.Flags().Bool("semantic", false, "Compare files key by key instead of byte for byte (ignores formatting and key order)")
+}
+
+type FileCheck struct {
+	File   LocalizationFile
+	Stale  bool
+	Reason string
+}
+
+func newFileCheckReport(project string, check FileCheck) FileReport {
+	status := "up_to_date"
+	if check.Stale {
+		status = "stale"
+	}
+
+	report := FileReport{Project: project, File: filepath.Clean(check.File.File), LocaleCode: check.File.LocaleCode, Status: status}
+	if check.Stale {
+		report.Error = &ErrorReport{Code: "stale", Message: check.Reason}
+	}
+
+	return report
+}
+
+func checkLocalizationFiles(ctx context.Context, config PullConfig, semantic bool) ([]FileCheck, error) {
+	apiClient := config.ApiClient
+	ctx = newApiContext(ctx, config.ApiToken)
+
+	checks := make([]FileCheck, len(config.Files))
+
+	results := runFileTasks(config.Files, config.Concurrency, func(i int, v LocalizationFile) error {
+		checks[i].File = v
+
+		remote, _, err := downloadLocalizationFile(ctx, apiClient, config, v)
+		if err != nil {
+			return err
+		}
+
+		local, err := os.ReadFile(filepath.Clean(v.File))
+		if errors.Is(err, os.ErrNotExist) {
+			checks[i].Stale, checks[i].Reason = true, "missing locally"
+			return nil
+		}
+		if err != nil {
+			return errors.New(fmt.Sprintf("Failed to read localization file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+		}
+
+		if semantic {
+			checks[i].Stale = !isSameContent(v.FileType, local, remote)
+		} else {
+			checks[i].Stale = !bytes.Equal(local, remote)
+		}
+		if checks[i].Stale {
+			checks[i].Reason = "differs from Localizely"
+		}
+
+		return nil
+	})
+
+	return checks, joinFileErrors(results)
+}
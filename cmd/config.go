@@ -0,0 +1,69 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect the localizely.yml config file",
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:     "validate",
+	Short:   "Validate the localizely.yml config file",
+	Long:    "Validate the localizely.yml config file\n\nThe config file is checked against the schema of all supported keys, and every problem (e.g. a misspelled key, a missing required key or an invalid value) is reported with its line and column.\nThe push and pull commands run the same validation before they start.",
+	Example: "  localizely-cli config validate --config apps/web/localizely.yml",
+	Run: func(cmd *cobra.Command, args []string) {
+		path := viper.ConfigFileUsed()
+		if path == "" {
+			checkError(newConfigError(fmt.Sprintf("The %s file was not found.\n\nPlease create it using the \"localizely-cli init\" command, or set its path using the config flag.\n\n", LocalizelyYamlFile)))
+		}
+
+		problems, err := validateConfigFile(path)
+		checkError(err)
+
+		if len(problems) > 0 {
+			fmt.Fprint(os.Stderr, formatConfigProblems(path, problems))
+			color.Set(color.FgRed)
+			fmt.Fprintf(os.Stderr, "\nFound %d problems in the '%s' file\n", len(problems), path)
+			color.Unset()
+			reportFailureCode("invalid_config", fmt.Sprintf("Found %d problems in the '%s' file", len(problems), path))
+			os.Exit(ExitCodeConfig)
+		}
+
+		reportSuccess("The '%s' file is valid", path)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(configCmd)
+
+	configCmd.AddCommand(configValidateCmd)
+}
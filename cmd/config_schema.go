@@ -0,0 +1,126 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+
+	"github.com/spf13/cobra"
+)
+
+var configSchemaCmd = &cobra.Command{
+	Use:     "schema",
+	Short:   "Print the JSON Schema of the localizely.yml config file",
+	Long:    "Print the JSON Schema of the localizely.yml config file\n\nThe schema describes every supported key, its type, allowed values and description, and is generated from the same definition the CLI uses to validate the config file.\nEditors can use it to autocomplete and validate the config file (e.g. the YAML extension for VS Code).",
+	Example: "  localizely-cli config schema > localizely.schema.json",
+	Run: func(cmd *cobra.Command, args []string) {
+		schema := buildJsonSchema(configSchema)
+		schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+		schema["title"] = "Localizely CLI config file (" + LocalizelyYamlFile + ")"
+
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err := encoder.Encode(schema)
+		checkError(err)
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configSchemaCmd)
+}
+
+// buildJsonSchema converts the config key to a JSON Schema (draft-07) object.
+func buildJsonSchema(key *ConfigKey) map[string]interface{} {
+	schema := map[string]interface{}{}
+	if key.Description != "" {
+		schema["description"] = key.Description
+	}
+
+	// The config file validation always rejects unsupported keys, so no value is valid
+	if key.Unsupported {
+		schema["not"] = map[string]interface{}{}
+		return schema
+	}
+
+	switch key.Type {
+	case configTypeObject:
+		properties := map[string]interface{}{}
+		var required []string
+		for _, v := range key.Keys {
+			properties[v.Name] = buildJsonSchema(v)
+			if v.Required {
+				required = append(required, v.Name)
+			}
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+		schema["additionalProperties"] = false
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		if len(key.OneOf) > 0 {
+			var oneOf []interface{}
+			for _, v := range key.OneOf {
+				oneOf = append(oneOf, map[string]interface{}{"required": []string{v}})
+			}
+			schema["oneOf"] = oneOf
+		}
+
+	case configTypeList:
+		schema["type"] = "array"
+		schema["items"] = buildJsonSchema(key.Items)
+
+	case configTypeLocales:
+		schema["anyOf"] = []interface{}{
+			map[string]interface{}{"const": "all"},
+			map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+		}
+
+	case configTypeDuration:
+		// A duration such as 30s, 1m or 1h30m, or a number of nanoseconds
+		schema["type"] = []string{"string", "integer"}
+		schema["pattern"] = `^([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$`
+
+	case configTypeBoolean, configTypeInteger:
+		schema["type"] = key.Type
+
+	default:
+		schema["type"] = "string"
+	}
+
+	if len(key.Enum) > 0 {
+		var enum []interface{}
+		for _, v := range key.Enum {
+			enum = append(enum, v)
+			// Unquoted numeric values (e.g. config_version: 1.0) are numbers in YAML, and the config file validation compares them as numbers
+			if n, err := strconv.ParseFloat(v, 64); err == nil {
+				enum = append(enum, n)
+				schema["type"] = []string{"string", "number"}
+			}
+		}
+		schema["enum"] = enum
+	}
+
+	return schema
+}
@@ -0,0 +1,320 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var showCommandOpt = []string{
+	"pull",
+	"push",
+}
+
+// ConfigValue is a resolved config value with its source: a flag, an environment variable, the config file (with the line), or the default.
+type ConfigValue struct {
+	Type    string `json:"type,omitempty"`
+	Project string `json:"project,omitempty"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Source  string `json:"source"`
+}
+
+var configShowCmd = &cobra.Command{
+	Use:     "show",
+	Short:   "Show the effective configuration of a command",
+	Long:    "Show the effective configuration of a command\n\nThe configuration is merged from the flags, the LOCALIZELY_* environment variables, the localizely.yml file and the ~/.localizely/credentials.yaml file, in that order of precedence.\nEach value is printed together with its source, and the API token is redacted.\nThe flags of the shown command can be passed to see how they change the configuration.",
+	Example: "  localizely-cli config show --command push --branch feature-x",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		command, _ := cmd.Flags().GetString("command")
+		if command == "push" {
+			bindUploadFlags(cmd)
+		} else {
+			bindDownloadFlags(cmd)
+		}
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		command, err := cmd.Flags().GetString("command")
+		checkError(err)
+
+		err = validateShowCommand(command)
+		checkError(err)
+
+		var root *yaml.Node
+		if path := viper.ConfigFileUsed(); path != "" {
+			root, err = readConfigFileNode(path)
+			checkError(err)
+		}
+
+		first := true
+		err = forEachProject(func(project string) error {
+			values, err := readConfigValues(cmd, command, project, root)
+			if err != nil {
+				return err
+			}
+
+			if isStructuredOutput() {
+				for _, v := range values {
+					v.Project = project
+					addConfigReport(v)
+				}
+				return nil
+			}
+
+			printConfigValues(command, project, values, first)
+			first = false
+			return nil
+		})
+		checkError(err)
+
+		if isStructuredOutput() {
+			reportSuccess("Effective configuration of the %s command", command)
+		}
+	},
+}
+
+func init() {
+	configCmd.AddCommand(configShowCmd)
+
+	configShowCmd.Flags().String("command", "pull", "Command to show the configuration for\n"+formatOptions(showCommandOpt, 1, "unordered"))
+
+	// Accept the flags of both commands, so their effect on the configuration can be shown
+	addDownloadFlags(configShowCmd)
+	push := &cobra.Command{}
+	addUploadFlags(push)
+	push.Flags().Bool("skip-unchanged", false, "Skip files whose content has not changed since the last push to the same project and branch")
+	push.Flags().VisitAll(func(flag *pflag.Flag) {
+		if configShowCmd.Flags().Lookup(flag.Name) == nil {
+			configShowCmd.Flags().AddFlag(flag)
+		}
+	})
+}
+
+func validateShowCommand(command string) error {
+	for _, opt := range showCommandOpt {
+		if opt == command {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("The command has invalid value.\n\nAvailable options:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(showCommandOpt, 1, "unordered"))
+	return newConfigError(msg)
+}
+
+// readConfigValues reads the configuration the command would use, in the same way as the command itself.
+func readConfigValues(cmd *cobra.Command, command string, project string, root *yaml.Node) ([]ConfigValue, error) {
+	flags := append([]ConfigFlag{{"profile", "profile"}, {"api_url", "api-url"}}, downloadConfigFlags...)
+	if command == "push" {
+		flags = append([]ConfigFlag{{"profile", "profile"}, {"api_url", "api-url"}}, uploadConfigFlags...)
+	}
+
+	value := func(key string, v string) ConfigValue {
+		return ConfigValue{Key: key, Value: v, Source: configSource(cmd, flags, project, root, key)}
+	}
+
+	if command == "push" {
+		config, err := readPushConfig(cmd.Context(), false)
+		if err != nil {
+			return nil, err
+		}
+
+		var files []string
+		for _, v := range config.Files {
+			files = append(files, fmt.Sprintf("%s -> %s", filepath.Clean(v.File), v.LocaleCode))
+		}
+
+		return []ConfigValue{
+			value("profile", viper.GetString("profile")),
+			value("api_url", viper.GetString("api_url")),
+			value("api_token", redactApiToken(config.ApiToken)),
+			value("project_id", config.ProjectId),
+			value("branch", config.Branch),
+			value("max_retries", strconv.Itoa(config.RetryPolicy.MaxRetries)),
+			value("retry_timeout", config.RetryPolicy.Timeout.String()),
+			value("upload.files", strings.Join(files, "\n")),
+			value("upload.params.overwrite", strconv.FormatBool(config.Overwrite)),
+			value("upload.params.reviewed", strconv.FormatBool(config.Reviewed)),
+			value("upload.params.tag_added", strings.Join(config.TagAdded, ", ")),
+			value("upload.params.tag_updated", strings.Join(config.TagUpdated, ", ")),
+			value("upload.params.tag_removed", strings.Join(config.TagRemoved, ", ")),
+			value("upload.params.concurrency", strconv.Itoa(config.Concurrency)),
+			value("upload.params.skip_unchanged", strconv.FormatBool(config.SkipUnchanged)),
+		}, nil
+	}
+
+	config, err := readPullConfig(cmd.Context(), false)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	for _, v := range config.Files {
+		files = append(files, fmt.Sprintf("%s <- %s (%s)", filepath.Clean(v.File), v.LocaleCode, formatPlanValue(v.FileType)))
+	}
+
+	return []ConfigValue{
+		value("profile", viper.GetString("profile")),
+		value("api_url", viper.GetString("api_url")),
+		value("api_token", redactApiToken(config.ApiToken)),
+		value("project_id", config.ProjectId),
+		value("branch", config.Branch),
+		value("file_type", config.FileType),
+		value("max_retries", strconv.Itoa(config.RetryPolicy.MaxRetries)),
+		value("retry_timeout", config.RetryPolicy.Timeout.String()),
+		value("download.files", strings.Join(files, "\n")),
+		value("download.all_languages", strconv.FormatBool(config.AllLanguages)),
+		value("download.file_pattern", config.FilePattern),
+		value("download.params.export_empty_as", config.ExportEmptyAs),
+		value("download.params.include_tags", strings.Join(config.IncludeTags, ", ")),
+		value("download.params.exclude_tags", strings.Join(config.ExcludeTags, ", ")),
+		value("download.params.java_properties_encoding", config.JavaPropertiesEncoding),
+		value("download.params.concurrency", strconv.Itoa(config.Concurrency)),
+	}, nil
+}
+
+// configSource returns where the value of the key comes from, following the precedence of viper.
+func configSource(cmd *cobra.Command, flags []ConfigFlag, project string, root *yaml.Node, key string) string {
+	for _, v := range flags {
+		if v.Key != key {
+			continue
+		}
+		if flag := cmd.Flags().Lookup(v.Flag); flag != nil && flag.Changed {
+			return "flag --" + v.Flag
+		}
+	}
+
+	env := "LOCALIZELY_" + strings.ToUpper(key)
+	if _, ok := os.LookupEnv(env); ok {
+		return "env " + env
+	}
+
+	if line := findConfigKeyLine(root, project, key); line > 0 {
+		return fmt.Sprintf("%s:%d", formatConfigFileDisplayPath(), line)
+	}
+
+	if key == "api_token" {
+		// The api token file, the token command and the credentials file are not viper keys, so their source comes with the token
+		if token, source, err := readApiToken(); err == nil && token != "" && source != "" {
+			return source
+		}
+	}
+
+	return "default"
+}
+
+// findConfigKeyLine returns the line of the key in the config file, looking in the project entry first, or 0 if the key is not set.
+func findConfigKeyLine(root *yaml.Node, project string, key string) int {
+	if root == nil {
+		return 0
+	}
+
+	path := strings.Split(key, ".")
+
+	if project != "" {
+		if projects := findConfigValue(root, "projects"); projects != nil {
+			for _, v := range projects.Content {
+				if name := findConfigValue(v, "name"); name != nil && name.Value == project {
+					if node := findConfigKeyNode(v, path); node != nil {
+						return node.Line
+					}
+				}
+			}
+		}
+	}
+
+	if node := findConfigKeyNode(root, path); node != nil {
+		return node.Line
+	}
+
+	return 0
+}
+
+// findConfigKeyNode returns the node of the last key of the path, or nil if the path is not set.
+func findConfigKeyNode(node *yaml.Node, path []string) *yaml.Node {
+	for i, name := range path {
+		if node == nil || node.Kind != yaml.MappingNode {
+			return nil
+		}
+
+		var next *yaml.Node
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == name {
+				if i == len(path)-1 {
+					return node.Content[j]
+				}
+				next = node.Content[j+1]
+				break
+			}
+		}
+		node = next
+	}
+
+	return nil
+}
+
+func formatConfigFileDisplayPath() string {
+	return filepath.Join(configDir(), filepath.Base(viper.ConfigFileUsed()))
+}
+
+// redactApiToken keeps only the last characters of the token, so the output can be shared safely.
+func redactApiToken(apiToken string) string {
+	if apiToken == "" {
+		return ""
+	}
+	if len(apiToken) <= 8 {
+		return "****"
+	}
+
+	return "****" + apiToken[len(apiToken)-4:]
+}
+
+func printConfigValues(command string, project string, values []ConfigValue, first bool) {
+	if !first {
+		fmt.Println()
+	}
+
+	fmt.Printf("Command: %s\n", command)
+	if project != "" {
+		fmt.Printf("Project: %s\n", project)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, v := range values {
+		lines := strings.Split(formatPlanValue(v.Value), "\n")
+		fmt.Fprintf(w, "%s\t%s\t%s\n", v.Key, lines[0], v.Source)
+		for _, line := range lines[1:] {
+			fmt.Fprintf(w, "\t%s\t\n", line)
+		}
+	}
+	w.Flush()
+}
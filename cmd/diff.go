@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var diffCmd = &cobra.Command{
+	Use:     "diff",
+	Short:   "Show differences between local localization files and Localizely",
+	Long:    fmt.Sprintf("Show differences between local localization files and Localizely\n\nThe remote version of each download file is compared key by key with the local file:\n  +  key exists only in Localizely (it would be added by pull)\n  -  key exists only in the local file (it would be removed by pull)\n  ~  key has a different value in Localizely\n\nKey-level comparison is supported for the json, flutter_arb, android_xml, ios_strings, po and pot file types. Other file types are compared by content.\nThe command exits with status %d when differences exist.", ExitCodeStale),
+	Example: "  localizely-cli diff \\\n    --api-token 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef \\\n    --project-id 01234567-abcd-abcd-abcd-0123456789ab \\\n    --file-type json \\\n    --files \"file[0]=lang/en.json\",\"locale_code[0]=en\",\"file[1]=lang/de_DE.json\",\"locale_code[1]=de-DE\"",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindDownloadFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		configs, err := readPullConfigs(cmd.Context())
+		checkError(err)
+
+		changed, total := 0, 0
+		for i, config := range configs {
+			printProjectHeader(config.Project, i == 0)
+
+			diffs, err := diffLocalizationFiles(cmd.Context(), config)
+
+			for _, v := range diffs {
+				if v.HasChanges() {
+					changed++
+				}
+
+				if isStructuredOutput() {
+					addFileReport(newFileDiffReport(config.Project, v))
+				} else {
+					printFileDiff(v)
+				}
+			}
+			total += len(diffs)
+
+			checkError(err)
+		}
+
+		if changed > 0 {
+			fmt.Fprintf(os.Stderr, "%d of %d localization files differ from Localizely\n", changed, total)
+
+			reportFailureCode("changed", fmt.Sprintf("%d of %d localization files differ from Localizely", changed, total))
+			os.Exit(ExitCodeStale)
+		}
+
+		reportSuccess("Local files are in sync with Localizely")
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	addDownloadFlags(diffCmd)
+}
+
+type KeyDiff struct {
+	Key    string
+	Local  *string
+	Remote *string
+}
+
+// KeyDiffReport is a different key of the file, reported as added or removed by a pull, or changed in Localizely.
+type KeyDiffReport struct {
+	Key    string  `json:"key"`
+	Change string  `json:"change"`
+	Local  *string `json:"local,omitempty"`
+	Remote *string `json:"remote,omitempty"`
+}
+
+type FileDiff struct {
+	File LocalizationFile
+	// Keys is set only for file types that support key-level comparison
+	Keys           []KeyDiff
+	ContentDiffers bool
+	LocalMissing   bool
+	Compared       bool
+}
+
+// newFileDiffReport reports the file as in sync or changed, together with its different keys.
+func newFileDiffReport(project string, diff FileDiff) FileReport {
+	report := FileReport{Project: project, File: filepath.Clean(diff.File.File), LocaleCode: diff.File.LocaleCode, Status: "in_sync", ContentDiffers: diff.ContentDiffers}
+
+	for _, v := range diff.Keys {
+		change := "changed"
+		switch {
+		case v.Local == nil:
+			change = "added"
+		case v.Remote == nil:
+			change = "removed"
+		}
+		report.Keys = append(report.Keys, KeyDiffReport{Key: v.Key, Change: change, Local: v.Local, Remote: v.Remote})
+	}
+
+	switch {
+	case !diff.Compared:
+		report.Status = "failed"
+	case diff.LocalMissing:
+		report.Status = "missing_locally"
+	case diff.HasChanges():
+		report.Status = "changed"
+	}
+
+	return report
+}
+
+func (d FileDiff) HasChanges() bool {
+	return d.ContentDiffers || len(d.Keys) > 0
+}
+
+func diffLocalizationFiles(ctx context.Context, config PullConfig) ([]FileDiff, error) {
+	apiClient := config.ApiClient
+	ctx = newApiContext(ctx, config.ApiToken)
+
+	diffs := make([]FileDiff, len(config.Files))
+
+	results := runFileTasks(config.Files, config.Concurrency, func(i int, v LocalizationFile) error {
+		diffs[i].File = v
+
+		remote, _, err := downloadLocalizationFile(ctx, apiClient, config, v)
+		if err != nil {
+			return err
+		}
+
+		local, err := os.ReadFile(filepath.Clean(v.File))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return errors.New(fmt.Sprintf("Failed to read localization file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+		}
+
+		diff, err := compareLocalizationFile(v.FileType, v, local, remote)
+		if err != nil {
+			return err
+		}
+		diff.LocalMissing = local == nil
+
+		diffs[i] = diff
+
+		return nil
+	})
+
+	return diffs, joinFileErrors(results)
+}
+
+// compareLocalizationFile compares the local and remote content of the localization file, key by key when the file type supports it.
+func compareLocalizationFile(fileType string, file LocalizationFile, local []byte, remote []byte) (FileDiff, error) {
+	diff := FileDiff{File: file, Compared: true}
+
+	if !supportsKeyParsing(fileType) {
+		diff.ContentDiffers = !bytes.Equal(local, remote)
+		return diff, nil
+	}
+
+	localKeys := map[string]string{}
+	if local != nil {
+		var err error
+		localKeys, err = parseLocalizationFile(fileType, local)
+		if err != nil {
+			return diff, errors.New(fmt.Sprintf("Failed to parse localization file '%s'\nError: %v\n", filepath.Clean(file.File), err))
+		}
+	}
+
+	remoteKeys, err := parseLocalizationFile(fileType, remote)
+	if err != nil {
+		return diff, errors.New(fmt.Sprintf("Failed to parse localization file '%s' downloaded from Localizely\nError: %v\n", filepath.Clean(file.File), err))
+	}
+
+	diff.Keys = diffKeys(localKeys, remoteKeys)
+
+	return diff, nil
+}
+
+func diffKeys(local map[string]string, remote map[string]string) []KeyDiff {
+	var diffs []KeyDiff
+
+	for k, lv := range local {
+		rv, ok := remote[k]
+		if !ok {
+			diffs = append(diffs, KeyDiff{Key: k, Local: &lv})
+		} else if lv != rv {
+			diffs = append(diffs, KeyDiff{Key: k, Local: &lv, Remote: &rv})
+		}
+	}
+
+	for k, rv := range remote {
+		if _, ok := local[k]; !ok {
+			diffs = append(diffs, KeyDiff{Key: k, Remote: &rv})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+
+	return diffs
+}
+
+func printFileDiff(diff FileDiff) {
+	if !diff.Compared || !diff.HasChanges() {
+		return
+	}
+
+	header := fmt.Sprintf("%s (%s)", filepath.Clean(diff.File.File), diff.File.LocaleCode)
+	if diff.LocalMissing {
+		header += " - missing locally"
+	}
+	color.New(color.Bold).Println(header)
+
+	if diff.ContentDiffers {
+		fmt.Printf("  content differs\n\n")
+		return
+	}
+
+	for _, v := range diff.Keys {
+		switch {
+		case v.Local == nil:
+			color.Green("  + %s: %s", v.Key, strconv.Quote(*v.Remote))
+		case v.Remote == nil:
+			color.Red("  - %s: %s", v.Key, strconv.Quote(*v.Local))
+		default:
+			color.Yellow("  ~ %s: %s -> %s", v.Key, strconv.Quote(*v.Local), strconv.Quote(*v.Remote))
+		}
+	}
+	fmt.Println()
+}
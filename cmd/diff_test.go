@@ -0,0 +1,64 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"testing"
+)
+
+func TestNewFileDiffReport(t *testing.T) {
+	file := LocalizationFile{File: "lang/en.json", LocaleCode: "en"}
+	local := map[string]string{"bye": "Bye", "hello": "Hi", "same": "Same"}
+	remote := map[string]string{"hello": "Hello", "new": "", "same": "Same"}
+
+	report := newFileDiffReport("web", FileDiff{File: file, Compared: true, Keys: diffKeys(local, remote)})
+
+	if report.Status != "changed" {
+		t.Errorf("Status = %q, want %q", report.Status, "changed")
+	}
+
+	want := []struct {
+		key    string
+		change string
+		local  string
+		remote string
+	}{
+		{"bye", "removed", "Bye", ""},
+		{"hello", "changed", "Hi", "Hello"},
+		{"new", "added", "", ""},
+	}
+	if len(report.Keys) != len(want) {
+		t.Fatalf("Keys = %+v, want %d keys", report.Keys, len(want))
+	}
+	for i, w := range want {
+		got := report.Keys[i]
+		if got.Key != w.key || got.Change != w.change {
+			t.Errorf("Keys[%d] = %s %s, want %s %s", i, got.Key, got.Change, w.key, w.change)
+		}
+		if (got.Local != nil && *got.Local != w.local) || (got.Local == nil) != (w.change == "added") {
+			t.Errorf("Keys[%d].Local = %v, want %q", i, got.Local, w.local)
+		}
+		if (got.Remote != nil && *got.Remote != w.remote) || (got.Remote == nil) != (w.change == "removed") {
+			t.Errorf("Keys[%d].Remote = %v, want %q", i, got.Remote, w.remote)
+		}
+	}
+}
@@ -0,0 +1,178 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// Exit codes of the CLI, so scripts can react to the kind of failure, e.g. retry on network errors but not on auth errors.
+const (
+	// ExitCodeError is returned for failures that have no specific exit code, e.g. a failed write to the disk.
+	ExitCodeError = 1
+	// ExitCodeStale is returned when local files are not up to date with Localizely, so CI can tell it apart from other failures.
+	ExitCodeStale = 2
+	// ExitCodeConfig is returned when the configuration is invalid or incomplete, e.g. a missing project ID or an invalid config file.
+	ExitCodeConfig = 3
+	// ExitCodeAuth is returned when the API token is missing, or Localizely rejects it.
+	ExitCodeAuth = 4
+	// ExitCodeNotFound is returned when the project (or another resource) does not exist in Localizely.
+	ExitCodeNotFound = 5
+	// ExitCodeNetwork is returned when Localizely could not be reached, or failed with a transient error after the retries.
+	ExitCodeNetwork = 6
+	// ExitCodePartialFailure is returned when some files failed while the other files succeeded.
+	ExitCodePartialFailure = 7
+	// ExitCodeValidation is returned when Localizely rejects the request as invalid, e.g. an uploaded file it cannot import.
+	ExitCodeValidation = 8
+	// ExitCodeUsage is returned when the command is used incorrectly, e.g. with an unknown flag or an invalid flag value.
+	ExitCodeUsage = 64
+	// ExitCodeCanceled is returned when the command is interrupted (SIGINT or SIGTERM) or exceeds the timeout, following the shell convention for SIGINT.
+	ExitCodeCanceled = 130
+)
+
+// exitCodes maps the error codes of the json output to the exit codes.
+var exitCodes = map[string]int{
+	"stale":            ExitCodeStale,
+	"changed":          ExitCodeStale,
+	"config_error":     ExitCodeConfig,
+	"invalid_config":   ExitCodeConfig,
+	"auth_error":       ExitCodeAuth,
+	"unauthorized":     ExitCodeAuth,
+	"forbidden":        ExitCodeAuth,
+	"not_found":        ExitCodeNotFound,
+	"rate_limited":     ExitCodeNetwork,
+	"server_error":     ExitCodeNetwork,
+	"network_error":    ExitCodeNetwork,
+	"partial_failure":  ExitCodePartialFailure,
+	"validation_error": ExitCodeValidation,
+	"cancelled":        ExitCodeCanceled,
+	"timeout":          ExitCodeCanceled,
+}
+
+var errInterrupted = errors.New("The command was interrupted")
+
+var errTimeout = errors.New("The command exceeded the timeout")
+
+// ConfigError is an invalid or missing configuration value, e.g. an unknown file type or a missing project ID.
+type ConfigError struct {
+	Message string
+}
+
+func (e *ConfigError) Error() string {
+	return e.Message
+}
+
+func newConfigError(message string) error {
+	return &ConfigError{Message: message}
+}
+
+// AuthError is an API token that is missing or could not be read, before any request is made.
+type AuthError struct {
+	Message string
+}
+
+func (e *AuthError) Error() string {
+	return e.Message
+}
+
+func newAuthError(message string) error {
+	return &AuthError{Message: message}
+}
+
+// FileErrors are the failures of some of the files of a command.
+// Partial is set if the other files succeeded, so the command had an effect.
+type FileErrors struct {
+	Message string
+	Errs    []error
+	Partial bool
+}
+
+func (e *FileErrors) Error() string {
+	return e.Message
+}
+
+func (e *FileErrors) Unwrap() []error {
+	return e.Errs
+}
+
+// errorCode classifies the error, so tools can react to the kind of failure without parsing the message.
+// If all files failed, the error is classified by the first error of a known kind.
+func errorCode(err error) string {
+	switch context.Cause(commandCtx) {
+	case errInterrupted:
+		return "cancelled"
+	case errTimeout:
+		return "timeout"
+	}
+
+	var fileErrs *FileErrors
+	if errors.As(err, &fileErrs) && fileErrs.Partial {
+		return "partial_failure"
+	}
+
+	var configErr *ConfigError
+	if errors.As(err, &configErr) {
+		return "config_error"
+	}
+
+	var authErr *AuthError
+	if errors.As(err, &authErr) {
+		return "auth_error"
+	}
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) && apiErr.StatusCode != 0 {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			return "unauthorized"
+		case apiErr.StatusCode == http.StatusForbidden:
+			return "forbidden"
+		case apiErr.StatusCode == http.StatusNotFound:
+			return "not_found"
+		case apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusUnprocessableEntity:
+			return "validation_error"
+		case apiErr.StatusCode == http.StatusTooManyRequests:
+			return "rate_limited"
+		case apiErr.StatusCode >= http.StatusInternalServerError:
+			return "server_error"
+		}
+		return "api_error"
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) || apiErr != nil {
+		return "network_error"
+	}
+
+	return "error"
+}
+
+func exitCode(err error) int {
+	if code, ok := exitCodes[errorCode(err)]; ok {
+		return code
+	}
+
+	return ExitCodeError
+}
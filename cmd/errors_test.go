@@ -0,0 +1,118 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+)
+
+func TestExitCode(t *testing.T) {
+	apiError := func(status int) error {
+		return newApiError("Failed", &http.Response{StatusCode: status}, errors.New(http.StatusText(status)))
+	}
+	netErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+
+	tests := []struct {
+		name     string
+		err      error
+		wantCode string
+		wantExit int
+	}{
+		{"generic error", errors.New("Failed to write the file"), "error", ExitCodeError},
+		{"config error", newConfigError("Missing project ID"), "config_error", ExitCodeConfig},
+		{"wrapped config error", fmt.Errorf("Project 'web': %w", newConfigError("Missing project ID")), "config_error", ExitCodeConfig},
+		{"auth error", newAuthError("Missing API token"), "auth_error", ExitCodeAuth},
+		{"missing upload file", validateFilesExist([]LocalizationFile{{File: "missing/en.json", LocaleCode: "en"}}), "config_error", ExitCodeConfig},
+		{"401 unauthorized", apiError(http.StatusUnauthorized), "unauthorized", ExitCodeAuth},
+		{"403 forbidden", apiError(http.StatusForbidden), "forbidden", ExitCodeAuth},
+		{"404 not found", apiError(http.StatusNotFound), "not_found", ExitCodeNotFound},
+		{"429 too many requests", apiError(http.StatusTooManyRequests), "rate_limited", ExitCodeNetwork},
+		{"500 internal server error", apiError(http.StatusInternalServerError), "server_error", ExitCodeNetwork},
+		{"503 service unavailable", apiError(http.StatusServiceUnavailable), "server_error", ExitCodeNetwork},
+		{"400 bad request", apiError(http.StatusBadRequest), "validation_error", ExitCodeValidation},
+		{"422 unprocessable entity", apiError(http.StatusUnprocessableEntity), "validation_error", ExitCodeValidation},
+		{"409 conflict", apiError(http.StatusConflict), "api_error", ExitCodeError},
+		{"api error without response", newApiError("Failed", nil, netErr), "network_error", ExitCodeNetwork},
+		{"network error", fmt.Errorf("Failed to download: %w", netErr), "network_error", ExitCodeNetwork},
+		{
+			"partial failure",
+			&FileErrors{Message: "Failed", Errs: []error{apiError(http.StatusNotFound)}, Partial: true},
+			"partial_failure", ExitCodePartialFailure,
+		},
+		{
+			"all files failed",
+			&FileErrors{Message: "Failed", Errs: []error{errors.New("Failed to read"), apiError(http.StatusNotFound)}},
+			"not_found", ExitCodeNotFound,
+		},
+		{
+			"all files failed with generic errors",
+			&FileErrors{Message: "Failed", Errs: []error{errors.New("Failed to read")}},
+			"error", ExitCodeError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errorCode(tt.err); got != tt.wantCode {
+				t.Errorf("errorCode() = %q, want %q", got, tt.wantCode)
+			}
+			if got := exitCode(tt.err); got != tt.wantExit {
+				t.Errorf("exitCode() = %d, want %d", got, tt.wantExit)
+			}
+		})
+	}
+}
+
+func TestExitCodeCanceled(t *testing.T) {
+	defer func(ctx context.Context) { commandCtx = ctx }(commandCtx)
+
+	tests := []struct {
+		name     string
+		cause    error
+		wantCode string
+	}{
+		{"interrupted", errInterrupted, "cancelled"},
+		{"timeout", errTimeout, "timeout"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancelCause(context.Background())
+			cancel(tt.cause)
+			commandCtx = ctx
+
+			// The cause of the cancellation takes precedence over the error it caused
+			err := newApiError("Failed to download", nil, ctx.Err())
+			if got := errorCode(err); got != tt.wantCode {
+				t.Errorf("errorCode() = %q, want %q", got, tt.wantCode)
+			}
+			if got := exitCode(err); got != ExitCodeCanceled {
+				t.Errorf("exitCode() = %d, want %d", got, ExitCodeCanceled)
+			}
+		})
+	}
+}
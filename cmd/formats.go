@@ -0,0 +1,512 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// parseLocalizationFile parses the content of the localization file into a flat map of translation keys and values.
+// Only the file types returned by supportsKeyParsing are supported.
+func parseLocalizationFile(fileType string, data []byte) (map[string]string, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+
+	switch fileType {
+	case "json":
+		return parseJson(data, false)
+	case "flutter_arb":
+		return parseJson(data, true)
+	case "android_xml":
+		return parseAndroidXml(data)
+	case "ios_strings":
+		return parseIosStrings(data)
+	case "po", "pot":
+		return parsePo(data)
+	}
+
+	return nil, errors.New(fmt.Sprintf("parsing of the '%s' file type is not supported", fileType))
+}
+
+func supportsKeyParsing(fileType string) bool {
+	switch fileType {
+	case "json", "flutter_arb", "android_xml", "ios_strings", "po", "pot":
+		return true
+	}
+
+	return false
+}
+
+func parseJson(data []byte, skipMetadata bool) (map[string]string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]string{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var root map[string]interface{}
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	translations := map[string]string{}
+	for k, v := range root {
+		// ARB metadata (e.g. @@locale, @key) is not a translation
+		if skipMetadata && strings.HasPrefix(k, "@") {
+			continue
+		}
+		flattenJson(k, v, translations)
+	}
+
+	return translations, nil
+}
+
+func flattenJson(key string, value interface{}, translations map[string]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for k, nested := range v {
+			flattenJson(key+"."+k, nested, translations)
+		}
+	case []interface{}:
+		for i, nested := range v {
+			flattenJson(fmt.Sprintf("%s[%d]", key, i), nested, translations)
+		}
+	case nil:
+		translations[key] = ""
+	default:
+		translations[key] = fmt.Sprint(v)
+	}
+}
+
+type androidXmlResources struct {
+	Strings []struct {
+		Name  string `xml:"name,attr"`
+		Value string `xml:",innerxml"`
+	} `xml:"string"`
+	Plurals []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Quantity string `xml:"quantity,attr"`
+			Value    string `xml:",innerxml"`
+		} `xml:"item"`
+	} `xml:"plurals"`
+	StringArrays []struct {
+		Name  string `xml:"name,attr"`
+		Items []struct {
+			Value string `xml:",innerxml"`
+		} `xml:"item"`
+	} `xml:"string-array"`
+}
+
+func parseAndroidXml(data []byte) (map[string]string, error) {
+	translations := map[string]string{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return translations, nil
+	}
+
+	var resources androidXmlResources
+	if err := xml.Unmarshal(data, &resources); err != nil {
+		return nil, err
+	}
+
+	for _, v := range resources.Strings {
+		translations[v.Name] = androidXmlText(v.Value)
+	}
+	for _, v := range resources.Plurals {
+		for _, item := range v.Items {
+			translations[fmt.Sprintf("%s[%s]", v.Name, item.Quantity)] = androidXmlText(item.Value)
+		}
+	}
+	for _, v := range resources.StringArrays {
+		for i, item := range v.Items {
+			translations[fmt.Sprintf("%s[%d]", v.Name, i)] = androidXmlText(item.Value)
+		}
+	}
+
+	return translations, nil
+}
+
+// androidXmlText decodes the inner XML of a resource value, so the same text compares equal whether it is escaped, in a CDATA section or inline markup.
+// Entities and CDATA sections are decoded, inline elements (e.g. <b> or <xliff:g>) are kept as tags, and comments are dropped.
+func androidXmlText(innerXml string) string {
+	var sb strings.Builder
+
+	decoder := xml.NewDecoder(strings.NewReader(innerXml))
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return sb.String()
+		}
+		if err != nil {
+			return innerXml
+		}
+
+		switch v := token.(type) {
+		case xml.CharData:
+			sb.Write(v)
+		case xml.StartElement:
+			sb.WriteString("<" + xmlName(v.Name))
+			for _, attr := range v.Attr {
+				sb.WriteString(" " + xmlName(attr.Name) + "=\"")
+				xml.EscapeText(&sb, []byte(attr.Value))
+				sb.WriteString("\"")
+			}
+			sb.WriteString(">")
+		case xml.EndElement:
+			sb.WriteString("</" + xmlName(v.Name) + ">")
+		}
+	}
+}
+
+func xmlName(name xml.Name) string {
+	if name.Space != "" {
+		return name.Space + ":" + name.Local
+	}
+
+	return name.Local
+}
+
+func parseIosStrings(data []byte) (map[string]string, error) {
+	text, err := decodeUtf16(data)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &stringsParser{input: []rune(text)}
+	translations := map[string]string{}
+
+	for {
+		p.skipWhitespaceAndComments()
+		if p.eof() {
+			return translations, nil
+		}
+
+		key, err := p.readToken()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespaceAndComments()
+		if err := p.expect('='); err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespaceAndComments()
+		value, err := p.readToken()
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipWhitespaceAndComments()
+		if err := p.expect(';'); err != nil {
+			return nil, err
+		}
+
+		translations[key] = value
+	}
+}
+
+// decodeUtf16 converts UTF-16 encoded content (detected by its byte order mark) to a string. Other content is returned as is.
+func decodeUtf16(data []byte) (string, error) {
+	var bigEndian bool
+	switch {
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		bigEndian = true
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		bigEndian = false
+	default:
+		return string(data), nil
+	}
+
+	data = data[2:]
+	if len(data)%2 != 0 {
+		return "", errors.New("invalid UTF-16 content")
+	}
+
+	units := make([]uint16, len(data)/2)
+	for i := range units {
+		if bigEndian {
+			units[i] = uint16(data[2*i])<<8 | uint16(data[2*i+1])
+		} else {
+			units[i] = uint16(data[2*i+1])<<8 | uint16(data[2*i])
+		}
+	}
+
+	return string(utf16.Decode(units)), nil
+}
+
+type stringsParser struct {
+	input []rune
+	pos   int
+	line  int
+}
+
+func (p *stringsParser) eof() bool {
+	return p.pos >= len(p.input)
+}
+
+func (p *stringsParser) peek(offset int) rune {
+	if p.pos+offset >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos+offset]
+}
+
+func (p *stringsParser) next() rune {
+	r := p.input[p.pos]
+	p.pos++
+	if r == '\n' {
+		p.line++
+	}
+	return r
+}
+
+func (p *stringsParser) errorf(format string, args ...interface{}) error {
+	return errors.New(fmt.Sprintf("line %d: %s", p.line+1, fmt.Sprintf(format, args...)))
+}
+
+func (p *stringsParser) skipWhitespaceAndComments() {
+	for !p.eof() {
+		switch {
+		case p.peek(0) == ' ' || p.peek(0) == '\t' || p.peek(0) == '\r' || p.peek(0) == '\n':
+			p.next()
+		case p.peek(0) == '/' && p.peek(1) == '*':
+			p.next()
+			p.next()
+			for !p.eof() && !(p.peek(0) == '*' && p.peek(1) == '/') {
+				p.next()
+			}
+			if !p.eof() {
+				p.next()
+				p.next()
+			}
+		case p.peek(0) == '/' && p.peek(1) == '/':
+			for !p.eof() && p.peek(0) != '\n' {
+				p.next()
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *stringsParser) expect(r rune) error {
+	if p.eof() || p.peek(0) != r {
+		return p.errorf("expected '%c'", r)
+	}
+	p.next()
+	return nil
+}
+
+// readToken reads either a quoted string or an unquoted identifier.
+func (p *stringsParser) readToken() (string, error) {
+	if p.eof() {
+		return "", p.errorf("unexpected end of file")
+	}
+
+	if p.peek(0) != '"' {
+		var sb strings.Builder
+		for !p.eof() && (p.peek(0) == '_' || p.peek(0) == '.' || p.peek(0) == '-' || isAlphanumeric(p.peek(0))) {
+			sb.WriteRune(p.next())
+		}
+		if sb.Len() == 0 {
+			return "", p.errorf("unexpected character '%c'", p.peek(0))
+		}
+		return sb.String(), nil
+	}
+
+	p.next()
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+
+		r := p.next()
+		if r == '"' {
+			return sb.String(), nil
+		}
+		if r != '\\' {
+			sb.WriteRune(r)
+			continue
+		}
+
+		if p.eof() {
+			return "", p.errorf("unterminated string")
+		}
+
+		switch e := p.next(); e {
+		case 'n':
+			sb.WriteRune('\n')
+		case 't':
+			sb.WriteRune('\t')
+		case 'r':
+			sb.WriteRune('\r')
+		case 'U', 'u':
+			hex := ""
+			for i := 0; i < 4 && !p.eof(); i++ {
+				hex += string(p.next())
+			}
+			code, err := strconv.ParseUint(hex, 16, 32)
+			if err != nil {
+				return "", p.errorf("invalid unicode escape '\\%c%s'", e, hex)
+			}
+			sb.WriteRune(rune(code))
+		default:
+			sb.WriteRune(e)
+		}
+	}
+}
+
+func isAlphanumeric(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')
+}
+
+// parsePo parses gettext PO/POT files. Keys are the msgid, prefixed with the msgctxt when present.
+// Plural forms are stored as separate keys with the plural index in brackets.
+func parsePo(data []byte) (map[string]string, error) {
+	translations := map[string]string{}
+
+	var msgctxt, msgid string
+	var hasMsgid bool
+	msgstr := map[string]string{}
+	var current *string
+	var currentKey string
+	var inMsgstr bool
+
+	flush := func() {
+		if hasMsgid && msgid != "" {
+			key := msgid
+			if msgctxt != "" {
+				key = msgctxt + "|" + msgid
+			}
+			for k, v := range msgstr {
+				translations[key+k] = v
+			}
+		}
+		msgctxt, msgid, hasMsgid, current, currentKey, inMsgstr = "", "", false, nil, "", false
+		msgstr = map[string]string{}
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "\"") {
+			value, err := unquotePoString(line)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("line %d: %v", lineNumber, err))
+			}
+			if current == nil {
+				return nil, errors.New(fmt.Sprintf("line %d: unexpected string", lineNumber))
+			}
+			*current += value
+			if inMsgstr {
+				msgstr[currentKey] = *current
+			}
+			continue
+		}
+
+		keyword, rest, _ := strings.Cut(line, " ")
+		value, err := unquotePoString(strings.TrimSpace(rest))
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("line %d: %v", lineNumber, err))
+		}
+
+		switch {
+		case keyword == "msgctxt":
+			flush()
+			msgctxt = value
+			current, inMsgstr = &msgctxt, false
+		case keyword == "msgid":
+			if hasMsgid {
+				flush()
+			}
+			msgid, hasMsgid = value, true
+			current, inMsgstr = &msgid, false
+		case keyword == "msgid_plural":
+			pluralId := value
+			current, inMsgstr = &pluralId, false
+		case keyword == "msgstr" || strings.HasPrefix(keyword, "msgstr["):
+			v := value
+			currentKey = strings.TrimPrefix(keyword, "msgstr")
+			msgstr[currentKey] = v
+			current, inMsgstr = &v, true
+		default:
+			return nil, errors.New(fmt.Sprintf("line %d: unknown keyword '%s'", lineNumber, keyword))
+		}
+	}
+
+	if err := scanner.Err(); err != nil && err != io.EOF {
+		return nil, err
+	}
+
+	flush()
+
+	return translations, nil
+}
+
+func unquotePoString(s string) (string, error) {
+	if len(s) < 2 || !strings.HasPrefix(s, "\"") || !strings.HasSuffix(s, "\"") {
+		return "", errors.New(fmt.Sprintf("invalid string %s", s))
+	}
+
+	var sb strings.Builder
+	runes := []rune(s[1 : len(s)-1])
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '\\' || i == len(runes)-1 {
+			sb.WriteRune(runes[i])
+			continue
+		}
+
+		i++
+		switch runes[i] {
+		case 'n':
+			sb.WriteRune('\n')
+		case 't':
+			sb.WriteRune('\t')
+		case 'r':
+			sb.WriteRune('\r')
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+
+	return sb.String(), nil
+}
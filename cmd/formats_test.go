@@ -0,0 +1,256 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestParseLocalizationFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		data     string
+		want     map[string]string
+	}{
+		{
+			name:     "json nested objects, arrays and scalars",
+			fileType: "json",
+			data:     `{"title": "Hello", "nav": {"home": "Home", "items": ["One", "Two"]}, "count": 3, "empty": null}`,
+			want:     map[string]string{"title": "Hello", "nav.home": "Home", "nav.items[0]": "One", "nav.items[1]": "Two", "count": "3", "empty": ""},
+		},
+		{
+			name:     "json escapes",
+			fileType: "json",
+			data:     `{"quote": "Say \"hi\"", "newline": "a\nb", "unicode": "café"}`,
+			want:     map[string]string{"quote": `Say "hi"`, "newline": "a\nb", "unicode": "café"},
+		},
+		{
+			name:     "json with byte order mark",
+			fileType: "json",
+			data:     "\xef\xbb\xbf{\"a\": \"b\"}",
+			want:     map[string]string{"a": "b"},
+		},
+		{
+			name:     "json empty file",
+			fileType: "json",
+			data:     "  \n",
+			want:     map[string]string{},
+		},
+		{
+			name:     "flutter_arb skips metadata",
+			fileType: "flutter_arb",
+			data:     `{"@@locale": "en", "greeting": "Hello {name}", "@greeting": {"placeholders": {"name": {}}}}`,
+			want:     map[string]string{"greeting": "Hello {name}"},
+		},
+		{
+			name:     "android_xml strings, plurals and arrays",
+			fileType: "android_xml",
+			data: `<?xml version="1.0" encoding="utf-8"?>
+<resources>
+    <string name="app_name">My App</string>
+    <plurals name="songs">
+        <item quantity="one">%d song</item>
+        <item quantity="other">%d songs</item>
+    </plurals>
+    <string-array name="planets">
+        <item>Mercury</item>
+        <item>Venus</item>
+    </string-array>
+</resources>`,
+			want: map[string]string{"app_name": "My App", "songs[one]": "%d song", "songs[other]": "%d songs", "planets[0]": "Mercury", "planets[1]": "Venus"},
+		},
+		{
+			name:     "android_xml entities, CDATA and inline markup are equivalent",
+			fileType: "android_xml",
+			data: `<resources>
+    <string name="escaped">&lt;b&gt;Bold&lt;/b&gt; &amp; more</string>
+    <string name="cdata"><![CDATA[<b>Bold</b> & more]]></string>
+    <string name="markup"><b>Bold</b> &amp; more</string>
+    <string name="apostrophe">Don\'t</string>
+</resources>`,
+			want: map[string]string{"escaped": "<b>Bold</b> & more", "cdata": "<b>Bold</b> & more", "markup": "<b>Bold</b> & more", "apostrophe": `Don\'t`},
+		},
+		{
+			name:     "android_xml namespaced inline markup and comments",
+			fileType: "android_xml",
+			data: `<resources xmlns:xliff="urn:oasis:names:tc:xliff:document:1.2">
+    <string name="greeting">Hello <xliff:g id="name" example="Bob">%s</xliff:g><!-- the user name --></string>
+    <plurals name="files">
+        <item quantity="other"><![CDATA[<i>%d</i> files]]></item>
+    </plurals>
+</resources>`,
+			want: map[string]string{"greeting": `Hello <xliff:g id="name" example="Bob">%s</xliff:g>`, "files[other]": "<i>%d</i> files"},
+		},
+		{
+			name:     "ios_strings escapes and comments",
+			fileType: "ios_strings",
+			data: `/* Title of the screen */
+"title" = "Hello";
+// Line comment
+"quote" = "Say \"hi\"";
+"multiline" = "a\nb\tc";
+"unicode" = "caf\U00E9";
+"backslash" = "a\\b";
+unquoted_key = "value";`,
+			want: map[string]string{"title": "Hello", "quote": `Say "hi"`, "multiline": "a\nb\tc", "unicode": "café", "backslash": `a\b`, "unquoted_key": "value"},
+		},
+		{
+			name:     "ios_strings UTF-16 little endian",
+			fileType: "ios_strings",
+			data:     "\xff\xfe\"\x00a\x00\"\x00=\x00\"\x00b\x00\"\x00;\x00",
+			want:     map[string]string{"a": "b"},
+		},
+		{
+			name:     "ios_strings UTF-16 big endian",
+			fileType: "ios_strings",
+			data:     "\xfe\xff\x00\"\x00a\x00\"\x00=\x00\"\x00b\x00\"\x00;",
+			want:     map[string]string{"a": "b"},
+		},
+		{
+			name:     "po header is skipped and comments are ignored",
+			fileType: "po",
+			data: `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+# Translator comment
+#: src/app.js:12
+#, fuzzy
+msgid "Hello"
+msgstr "Hallo"
+
+#~ msgid "Obsolete"
+#~ msgstr "Veraltet"
+`,
+			want: map[string]string{"Hello": "Hallo"},
+		},
+		{
+			name:     "po multiline strings",
+			fileType: "po",
+			data: `msgid ""
+"Hello "
+"world"
+msgstr ""
+"Hallo "
+"Welt"
+`,
+			want: map[string]string{"Hello world": "Hallo Welt"},
+		},
+		{
+			name:     "po plurals and context",
+			fileType: "po",
+			data: `msgctxt "menu"
+msgid "Open"
+msgstr "Öffnen"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+`,
+			want: map[string]string{"menu|Open": "Öffnen", "%d file[0]": "%d Datei", "%d file[1]": "%d Dateien"},
+		},
+		{
+			name:     "po escapes",
+			fileType: "po",
+			data: `msgid "Say \"hi\"\n"
+msgstr "Sag \"hallo\"\n\ttab \\ backslash"
+`,
+			want: map[string]string{"Say \"hi\"\n": "Sag \"hallo\"\n\ttab \\ backslash"},
+		},
+		{
+			name:     "pot with empty translations",
+			fileType: "pot",
+			data: `msgid "Hello"
+msgstr ""
+`,
+			want: map[string]string{"Hello": ""},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLocalizationFile(tt.fileType, []byte(tt.data))
+			if err != nil {
+				t.Fatalf("parseLocalizationFile() error = %v", err)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("parseLocalizationFile() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLocalizationFileErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		data     string
+	}{
+		{"json syntax error", "json", `{"a": `},
+		{"json top-level array", "json", `["a"]`},
+		{"android_xml syntax error", "android_xml", `<resources><string name="a">b</resources>`},
+		{"ios_strings missing semicolon", "ios_strings", `"a" = "b"`},
+		{"ios_strings unterminated string", "ios_strings", `"a" = "b;`},
+		{"ios_strings invalid unicode escape", "ios_strings", `"a" = "\Uzzzz";`},
+		{"ios_strings odd UTF-16 length", "ios_strings", "\xff\xfe\"\x00a"},
+		{"po unknown keyword", "po", "msgid \"a\"\nmsgfoo \"b\"\n"},
+		{"po unquoted string", "po", "msgid a\n"},
+		{"po string without keyword", "po", "\"a\"\n"},
+		{"unsupported file type", "csv", "a,b"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseLocalizationFile(tt.fileType, []byte(tt.data)); err == nil {
+				t.Errorf("parseLocalizationFile() error = nil, want an error")
+			}
+		})
+	}
+}
+
+func TestIsSameContent(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileType string
+		a        string
+		b        string
+		want     bool
+	}{
+		{"identical bytes", "csv", "a,b", "a,b", true},
+		{"different bytes of a type without parsing", "csv", "a,b", "a, b", false},
+		{"json key order and formatting", "json", `{"a": "1", "b": "2"}`, "{\n  \"b\": \"2\",\n  \"a\": \"1\"\n}", true},
+		{"json different value", "json", `{"a": "1"}`, `{"a": "2"}`, false},
+		{"json invalid content", "json", `{"a": "1"}`, `{"a": `, false},
+		{"po comments and line wrapping", "po", "# comment\nmsgid \"a\"\nmsgstr \"b c\"\n", "msgid \"a\"\nmsgstr \"\"\n\"b \"\n\"c\"\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSameContent(tt.fileType, []byte(tt.a), []byte(tt.b)); got != tt.want {
+				t.Errorf("isSameContent() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
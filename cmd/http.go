@@ -0,0 +1,87 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/spf13/viper"
+)
+
+// newHttpClient returns the HTTP client shared by all requests of the CLI.
+// The proxy is read from the HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables, and the CA certificates are added to the system ones.
+func newHttpClient() (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	tlsConfig, err := newTlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	transport.TLSClientConfig = tlsConfig
+
+	return &http.Client{
+		Transport: transport,
+		Timeout:   viper.GetDuration("request_timeout"),
+	}, nil
+}
+
+func newTlsConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if path := configPath("ca_cert"); path != "" {
+
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, newConfigError(fmt.Sprintf("Failed to read the CA certificate '%s'\nError: %v\n", path, err))
+		}
+
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(b) {
+			return nil, newConfigError(fmt.Sprintf("Failed to read the CA certificate '%s'\nError: no PEM certificates found\n", path))
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	clientCert, clientKey := configPath("client_cert"), configPath("client_key")
+	if clientCert != "" || clientKey != "" {
+		if clientCert == "" || clientKey == "" {
+			msg := "Both the client certificate and the client key are required for client authentication.\n\nPlease set them using one of the available options:\n- client_cert and client_key keys in the localizely.yml file\n- LOCALIZELY_CLIENT_CERT and LOCALIZELY_CLIENT_KEY environment variables\n- client-cert and client-key flags\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n"
+			return nil, newConfigError(msg)
+		}
+
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, newConfigError(fmt.Sprintf("Failed to read the client certificate '%s'\nError: %v\n", clientCert, err))
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return tlsConfig, nil
+}
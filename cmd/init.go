@@ -34,6 +34,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
@@ -67,6 +68,8 @@ config_version: 1.0 # Required. Only 1.0 available
 project_id: c776c33e-f428-4c91-87e1-a6a18c1554fe # Required. Your project ID from: https://app.localizely.com/projects
 file_type: flutter_arb # Required. Available values : android_xml, ios_strings, ios_stringsdict, java_properties, rails_yaml, angular_xlf, flutter_arb, dotnet_resx, po, pot, json, csv, xlsx
 branch: main # Optional. Your branch in Localizely project to sync files with.
+max_retries: 3 # Optional, default: 3. Maximum number of retries for transient API failures (e.g. 429, 503, connection errors).
+retry_timeout: 1m # Optional, default: 1m. Maximum total time to spend on retries of a single request. Set to 0 for no limit.
 upload: # Required.
   files: # Required. List of files for upload to Localizely. Usually, it is just one file used for the main locale
     - file: lib/l10n/intl_en.arb # Required. Path to the translation file
@@ -80,12 +83,23 @@ upload: # Required.
       - removed
     tag_updated: # Optional. List of tags to add to updated translations from uploading file.
       - updated
+    concurrency: 1 # Optional, default: 1. Number of files to upload in parallel.
+    skip_unchanged: false # Optional, default: false. Skip files whose content has not changed since the last push to the same project and branch.
 download: # Required.
   files: # Required. List of files for download from Localizely.
     - file: lib/l10n/intl_en.arb # Required. Path to the translation file
       locale_code: en # Required. Locale code for the file. Examples: en, de-DE, zh-Hans-CN
     - file: lib/l10n/intl_de.arb # Required. Path to the translation file
       locale_code: de # Required. Locale code for the file. Examples: en, de-DE, zh-Hans-CN
+    - file: lib/l10n/intl_{locale_underscore}.arb # Path pattern with locale placeholders: {locale}, {language}, {region}, {script}, {locale_underscore}, {android_locale}
+      locales: # Required for path patterns instead of locale_code. List of locale codes to expand the pattern for, or 'all' for all project languages
+        - fr
+        - pt-BR
+    - file: web/i18n/de.json # Required. Path to the translation file
+      locale_code: de # Required. Locale code for the file. Examples: en, de-DE, zh-Hans-CN
+      file_type: json # Optional. File type of this file, if it differs from the global file_type. The java_properties_encoding param can be set per file the same way
+  all_languages: false # Optional, default: false. Download all languages of the project. Languages not listed in the files are saved to the path derived from the file_pattern.
+  file_pattern: lib/l10n/intl_{locale_underscore}.arb # Required if all_languages is true. Path pattern for languages not listed in the files.
   params:
     export_empty_as: empty # Optional, default: empty. How you would like empty translations to be exported. Allowed values are 'empty' to keep empty, 'main' to replace with the main language value, or 'skip' to omit.
     exclude_tags: # Optional. List of tags to be excluded from the download. If not set, all string keys will be considered for download.
@@ -93,6 +107,7 @@ download: # Required.
     include_tags: # Optional. List of tags to be downloaded. If not set, all string keys will be considered for download.
       - new
     java_properties_encoding: utf_8 # Optional, default: latin_1. (Only for Java .properties files download) Character encoding. Available values : 'utf_8', 'latin_1'
+    concurrency: 1 # Optional, default: 1. Number of files to download in parallel.
 `
 
 func scanApiToken(apiToken *string) error {
@@ -176,7 +191,7 @@ func scanFiles(localizationFiles *[]LocalizationFile, section string) error {
 		var file string
 		var next string
 
-		fmt.Println()
+		fmt.Fprintln(textOutput())
 
 		for {
 			err := scan(fmt.Sprintf("Enter locale code of the file you would like to %s (e.g. en, fr-FR, zh-Hans-CN):", action), &localeCode)
@@ -239,7 +254,7 @@ func scanFiles(localizationFiles *[]LocalizationFile, section string) error {
 }
 
 func scan(message string, value *string) error {
-	fmt.Print(message + " ")
+	fmt.Fprint(textOutput(), message+" ")
 
 	reader := bufio.NewReader(os.Stdin)
 	line, err := reader.ReadString('\n')
@@ -251,8 +266,35 @@ func scan(message string, value *string) error {
 	return nil
 }
 
-func createCredentialsYamlFile(apiToken string) error {
-	bytes, err := yaml.Marshal(CredentialsYaml{ApiToken: apiToken})
+// saveApiToken saves the api token of the profile (or the top-level api token if the profile is empty), keeping the other profiles in the credentials file.
+func saveApiToken(profile string, apiToken string) error {
+	credentialsYaml, err := readCredentialsYaml()
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	if profile == "" {
+		if credentialsYaml.ApiToken != "" {
+			fmt.Fprintf(os.Stderr, "\nOverwriting the api token in the '%s' file\n", CredentialsYamlFile)
+		}
+		credentialsYaml.ApiToken = apiToken
+	} else {
+		if credentialsYaml.Profiles[profile].ApiToken != "" {
+			fmt.Fprintf(os.Stderr, "\nOverwriting the api token of the '%s' profile in the '%s' file\n", profile, CredentialsYamlFile)
+		}
+		if credentialsYaml.Profiles == nil {
+			credentialsYaml.Profiles = map[string]CredentialsProfile{}
+		}
+		p := credentialsYaml.Profiles[profile]
+		p.ApiToken = apiToken
+		credentialsYaml.Profiles[profile] = p
+	}
+
+	return writeCredentialsYaml(credentialsYaml)
+}
+
+func writeCredentialsYaml(credentialsYaml CredentialsYaml) error {
+	bytes, err := yaml.Marshal(credentialsYaml)
 	if err != nil {
 		return err
 	}
@@ -269,11 +311,8 @@ func createCredentialsYamlFile(apiToken string) error {
 		return err
 	}
 
-	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
-		fmt.Fprintf(os.Stderr, "\nOverwriting the content of the '%s' file\n", CredentialsYamlFile)
-	}
-
-	return os.WriteFile(path, bytes, 0666)
+	// The file holds api tokens, so it is readable only by the user
+	return os.WriteFile(path, bytes, 0600)
 }
 
 func createLocalizelyYamlFile(projectId string, fileType string, uploadFiles []LocalizationFile, downloadFiles []LocalizationFile) error {
@@ -305,7 +344,7 @@ func checkIsConfigured() error {
 }
 
 func initInteractive() error {
-	fmt.Printf("\nRunning init command in interactive mode\n")
+	fmt.Fprintf(textOutput(), "\nRunning init command in interactive mode\n")
 	var err error
 
 	var apiToken string
@@ -338,19 +377,19 @@ func initInteractive() error {
 		return err
 	}
 
-	err = createCredentialsYamlFile(apiToken)
+	err = saveApiToken(viper.GetString("profile"), apiToken)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Failed to save api token\nError: %v\n", err))
 	}
 
-	color.Green("\nSuccessfully saved api token in the '%s' file", formatCredentialsYamlFilePath())
+	color.New(color.FgGreen).Fprintf(textOutput(), "\nSuccessfully saved api token in the '%s' file\n", formatCredentialsYamlFilePath())
 
 	err = createLocalizelyYamlFile(projectId, fileType, uploadFiles, downloadFiles)
 	if err != nil {
 		return errors.New(fmt.Sprintf("Failed to create '%s'\nError: %v\n", LocalizelyYamlFile, err))
 	}
 
-	color.Green("\nSuccessfully created '%s' file\nFor advanced configuration options, see https://localizely.com/configuration-file/", LocalizelyYamlFile)
+	reportSuccess("\nSuccessfully created '%s' file\nFor advanced configuration options, see https://localizely.com/configuration-file/", LocalizelyYamlFile)
 
 	return nil
 }
@@ -363,7 +402,7 @@ func initTemplate() error {
 		return errors.New(fmt.Sprintf("Failed to generate template file\nError: %v\n", err))
 	}
 
-	color.Green("\nSuccessfully generated the '%s' template file\nFor more configuration details, see https://localizely.com/configuration-file/", LocalizelyYamlFile)
+	reportSuccess("\nSuccessfully generated the '%s' template file\nFor more configuration details, see https://localizely.com/configuration-file/", LocalizelyYamlFile)
 
 	return nil
 }
@@ -373,6 +412,8 @@ var initCmd = &cobra.Command{
 	Short: "Configure your Localizely client",
 	Long:  "Configure your Localizely client\n(Learn more here https://localizely.com/configuration-file/)\n",
 	Run: func(cmd *cobra.Command, args []string) {
+		restoreDefaultSignals()
+
 		mode, err := cmd.Flags().GetString("mode")
 		checkError(err)
 
@@ -382,7 +423,7 @@ var initCmd = &cobra.Command{
 		err = checkIsConfigured()
 		checkError(err)
 
-		fmt.Print(LocalizelyLogo)
+		fmt.Fprint(textOutput(), LocalizelyLogo)
 
 		if mode == "template" {
 			err = initTemplate()
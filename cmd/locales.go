@@ -0,0 +1,109 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"regexp"
+	"strings"
+)
+
+var localePlaceholderRegexp = regexp.MustCompile(`\{(locale|language|region|script|locale_underscore|android_locale)\}`)
+
+var localePlaceholders = []string{
+	"{locale}",
+	"{language}",
+	"{region}",
+	"{script}",
+	"{locale_underscore}",
+	"{android_locale}",
+}
+
+// splitLocaleCode splits the locale code (e.g. zh-Hans-CN or de_DE) into its language, script and region subtags.
+func splitLocaleCode(localeCode string) (language string, script string, region string) {
+	parts := strings.FieldsFunc(localeCode, func(r rune) bool {
+		return r == '-' || r == '_'
+	})
+
+	for i, v := range parts {
+		switch {
+		case i == 0:
+			language = v
+		case len(v) == 4 && script == "" && region == "":
+			script = v
+		case (len(v) == 2 || len(v) == 3) && region == "":
+			region = v
+		}
+	}
+
+	return language, script, region
+}
+
+// formatAndroidLocale formats the locale code as an Android resource directory name (e.g. values-de, values-de-rDE, values-b+zh+Hans+CN).
+func formatAndroidLocale(localeCode string) string {
+	language, script, region := splitLocaleCode(localeCode)
+
+	if script != "" {
+		qualifier := "values-b+" + language + "+" + script
+		if region != "" {
+			qualifier += "+" + region
+		}
+		return qualifier
+	}
+
+	if region != "" {
+		return "values-" + language + "-r" + region
+	}
+
+	return "values-" + language
+}
+
+func hasLocalePlaceholders(path string) bool {
+	return localePlaceholderRegexp.MatchString(path)
+}
+
+// expandLocalePlaceholders replaces the locale placeholders in the path pattern with the values for the given locale code.
+func expandLocalePlaceholders(pattern string, localeCode string) string {
+	language, script, region := splitLocaleCode(localeCode)
+
+	return localePlaceholderRegexp.ReplaceAllStringFunc(pattern, func(placeholder string) string {
+		switch placeholder {
+		case "{locale}":
+			return localeCode
+		case "{language}":
+			return language
+		case "{region}":
+			return region
+		case "{script}":
+			return script
+		case "{locale_underscore}":
+			return strings.ReplaceAll(localeCode, "-", "_")
+		case "{android_locale}":
+			return formatAndroidLocale(localeCode)
+		}
+		return placeholder
+	})
+}
+
+// normalizeLocaleCode returns the locale code in a form suitable for comparison (e.g. de_de and de-DE are the same locale).
+func normalizeLocaleCode(localeCode string) string {
+	return strings.ToLower(strings.ReplaceAll(localeCode, "_", "-"))
+}
@@ -0,0 +1,102 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"golang.org/x/term"
+)
+
+var loginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Save your API token",
+	Long:  fmt.Sprintf("Save your API token\n\nThe API token is saved in the %s file, as the top-level api token or, with the profile flag, as the api token of the named profile.\nThe token is entered with hidden input, or read from the standard input with the token-stdin flag (e.g. in scripts).", formatCredentialsYamlFilePath()),
+	Example: "  localizely-cli login --profile acme\n" +
+		"  echo \"$LOCALIZELY_TOKEN\" | localizely-cli login --token-stdin",
+	Run: func(cmd *cobra.Command, args []string) {
+		restoreDefaultSignals()
+
+		tokenStdin, err := cmd.Flags().GetBool("token-stdin")
+		checkError(err)
+
+		apiToken, err := readLoginApiToken(tokenStdin)
+		checkError(err)
+
+		profile := viper.GetString("profile")
+
+		err = saveApiToken(profile, apiToken)
+		if err != nil {
+			checkError(errors.New(fmt.Sprintf("Failed to save api token\nError: %v\n", err)))
+		}
+
+		if profile != "" {
+			reportSuccess("Successfully saved api token of the '%s' profile in the '%s' file", profile, formatCredentialsYamlFilePath())
+		} else {
+			reportSuccess("Successfully saved api token in the '%s' file", formatCredentialsYamlFilePath())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(loginCmd)
+
+	loginCmd.Flags().Bool("token-stdin", false, "Read the API token from the standard input")
+}
+
+func readLoginApiToken(tokenStdin bool) (string, error) {
+	var apiToken string
+
+	if tokenStdin {
+		b, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("Failed to read API token\nError: %v\n", err))
+		}
+		apiToken = string(b)
+	} else {
+		fd := int(os.Stdin.Fd())
+		if !term.IsTerminal(fd) {
+			return "", newConfigError("The standard input is not a terminal.\n\nPlease use the token-stdin flag to read the API token from the standard input.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n")
+		}
+
+		fmt.Fprint(textOutput(), "Enter your API token (from https://app.localizely.com/account): ")
+		b, err := term.ReadPassword(fd)
+		fmt.Fprintln(textOutput())
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("Failed to read API token\nError: %v\n", err))
+		}
+		apiToken = string(b)
+	}
+
+	apiToken = strings.TrimSpace(apiToken)
+	if apiToken == "" {
+		return "", newAuthError("The API token was not provided.\n\nTo create a new API token, please visit https://app.localizely.com/account.\n\n")
+	}
+
+	return apiToken, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var logoutCmd = &cobra.Command{
+	Use:     "logout",
+	Short:   "Remove your saved API token",
+	Long:    fmt.Sprintf("Remove your saved API token\n\nThe top-level api token or, with the profile flag, the named profile is removed from the %s file.", formatCredentialsYamlFilePath()),
+	Example: "  localizely-cli logout --profile acme",
+	Run: func(cmd *cobra.Command, args []string) {
+		profile := viper.GetString("profile")
+
+		removed, err := removeApiToken(profile)
+		if err != nil {
+			checkError(errors.New(fmt.Sprintf("Failed to remove api token\nError: %v\n", err)))
+		}
+
+		switch {
+		case !removed:
+			reportWarning("No api token is saved in the '%s' file", formatCredentialsYamlFilePath())
+		case profile != "":
+			reportSuccess("Successfully removed the '%s' profile from the '%s' file", profile, formatCredentialsYamlFilePath())
+		default:
+			reportSuccess("Successfully removed api token from the '%s' file", formatCredentialsYamlFilePath())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(logoutCmd)
+}
+
+// removeApiToken removes the api token of the profile (or the top-level api token if the profile is empty) and reports whether there was one.
+// The credentials file is removed when no api token is left in it.
+func removeApiToken(profile string) (bool, error) {
+	credentialsYaml, err := readCredentialsYaml()
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if profile == "" {
+		if credentialsYaml.ApiToken == "" {
+			return false, nil
+		}
+		credentialsYaml.ApiToken = ""
+	} else {
+		if _, ok := credentialsYaml.Profiles[profile]; !ok {
+			return false, nil
+		}
+		delete(credentialsYaml.Profiles, profile)
+	}
+
+	if credentialsYaml.ApiToken == "" && credentialsYaml.ApiTokenCommand == "" && len(credentialsYaml.Profiles) == 0 {
+		return true, os.Remove(formatCredentialsYamlFilePath())
+	}
+
+	return true, writeCredentialsYaml(credentialsYaml)
+}
@@ -0,0 +1,237 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// CommandReport is the result of a command, printed with the json output, or as the last line of the ndjson output.
+type CommandReport struct {
+	Type       string        `json:"type,omitempty"`
+	Command    string        `json:"command"`
+	Success    bool          `json:"success"`
+	Message    string        `json:"message,omitempty"`
+	DurationMs int64         `json:"duration_ms"`
+	Files      []FileReport  `json:"files,omitempty"`
+	Config     []ConfigValue `json:"config,omitempty"`
+	// Token is reported only by the whoami command
+	Token *TokenReport `json:"token,omitempty"`
+	Error *ErrorReport `json:"error,omitempty"`
+}
+
+// FileReport is the result of a single file, printed as a line of the ndjson output as soon as it is known.
+type FileReport struct {
+	Type       string       `json:"type,omitempty"`
+	Project    string       `json:"project,omitempty"`
+	File       string       `json:"file"`
+	LocaleCode string       `json:"locale_code"`
+	Status     string       `json:"status"`
+	Bytes      int64        `json:"bytes"`
+	DurationMs int64        `json:"duration_ms"`
+	HttpStatus int          `json:"http_status,omitempty"`
+	Error      *ErrorReport `json:"error,omitempty"`
+	// The sync details are reported only by the status command
+	Upload   bool            `json:"upload,omitempty"`
+	Download bool            `json:"download,omitempty"`
+	LastPush *SyncStateEntry `json:"last_push,omitempty"`
+	LastPull *SyncStateEntry `json:"last_pull,omitempty"`
+	// The differences are reported only by the diff command
+	ContentDiffers bool            `json:"content_differs,omitempty"`
+	Keys           []KeyDiffReport `json:"keys,omitempty"`
+}
+
+type ErrorReport struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+var commandReport = &CommandReport{}
+
+var commandStart = time.Now()
+
+func startCommandReport(cmd *cobra.Command) {
+	commandReport = &CommandReport{Command: strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")}
+	commandStart = time.Now()
+}
+
+// isStructuredOutput reports whether the results are printed as JSON instead of text, so the text messages on the standard output are left out.
+func isStructuredOutput() bool {
+	output := viper.GetString("output")
+	return output == "json" || output == "ndjson"
+}
+
+// textOutput returns the writer for informational text, which goes to the standard error when the standard output is JSON.
+func textOutput() io.Writer {
+	if isStructuredOutput() {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+func newFileReport(project string, result FileResult, status string) FileReport {
+	report := FileReport{
+		Project:    project,
+		File:       filepath.Clean(result.File.File),
+		LocaleCode: result.File.LocaleCode,
+		Status:     status,
+		Bytes:      result.Bytes,
+		DurationMs: result.Duration.Milliseconds(),
+		HttpStatus: result.HttpStatus,
+	}
+
+	switch {
+	case result.Err != nil:
+		report.Status = "failed"
+		report.Error = newErrorReport(result.Err)
+	case result.Skipped:
+		report.Status = "skipped"
+	}
+
+	return report
+}
+
+func newErrorReport(err error) *ErrorReport {
+	return &ErrorReport{Code: errorCode(err), Message: strings.TrimSpace(err.Error())}
+}
+
+// reportFileResults prints the results of the files as text, or adds them to the report of the command.
+func reportFileResults(project string, results []FileResult, action string) {
+	if !isStructuredOutput() {
+		printFileResults(results, action)
+		return
+	}
+
+	for _, v := range results {
+		addFileReport(newFileReport(project, v, strings.ToLower(action)))
+	}
+}
+
+// reportPlan prints the plan of a dry run as text, or adds the planned files to the report of the command.
+func reportPlan(project string, files []LocalizationFile, printPlan func()) {
+	if !isStructuredOutput() {
+		printPlan()
+		return
+	}
+
+	for _, v := range files {
+		addFileReport(FileReport{Project: project, File: filepath.Clean(v.File), LocaleCode: v.LocaleCode, Status: "planned"})
+	}
+}
+
+func addFileReport(report FileReport) {
+	commandReport.Files = append(commandReport.Files, report)
+
+	if viper.GetString("output") == "ndjson" {
+		report.Type = "file"
+		printJsonLine(report)
+	}
+}
+
+// addConfigReport adds the config value to the report of the command, printed as a separate line with the ndjson output.
+func addConfigReport(value ConfigValue) {
+	commandReport.Config = append(commandReport.Config, value)
+
+	if viper.GetString("output") == "ndjson" {
+		value.Type = "config"
+		printJsonLine(value)
+	}
+}
+
+// reportSuccess prints the success message as text, or the report of the successful command.
+func reportSuccess(format string, a ...interface{}) {
+	if !isStructuredOutput() {
+		color.Green(format, a...)
+		return
+	}
+
+	commandReport.Success = true
+	commandReport.Message = strings.TrimSpace(fmt.Sprintf(format, a...))
+	printCommandReport()
+}
+
+// reportWarning prints the warning as text, or the report of the command, which succeeded with the warning as the message.
+func reportWarning(format string, a ...interface{}) {
+	if !isStructuredOutput() {
+		color.Yellow(format, a...)
+		return
+	}
+
+	commandReport.Success = true
+	commandReport.Message = strings.TrimSpace(fmt.Sprintf(format, a...))
+	printCommandReport()
+}
+
+// reportFailure prints the report of the failed command, if the results are printed as JSON. The error is printed as text by the caller.
+func reportFailure(err error) {
+	if !isStructuredOutput() {
+		return
+	}
+
+	commandReport.Success = false
+	commandReport.Error = newErrorReport(err)
+	printCommandReport()
+}
+
+// reportFailureCode prints the report of a command that failed without an error, e.g. because files are stale, if the results are printed as JSON.
+func reportFailureCode(code string, message string) {
+	if !isStructuredOutput() {
+		return
+	}
+
+	commandReport.Success = false
+	commandReport.Error = &ErrorReport{Code: code, Message: message}
+	printCommandReport()
+}
+
+func printCommandReport() {
+	report := *commandReport
+	report.DurationMs = time.Since(commandStart).Milliseconds()
+
+	if viper.GetString("output") == "ndjson" {
+		// The files and config values are already printed as separate lines
+		report.Type = "result"
+		report.Files = nil
+		report.Config = nil
+		printJsonLine(report)
+		return
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(report)
+}
+
+func printJsonLine(v interface{}) {
+	json.NewEncoder(os.Stdout).Encode(v)
+}
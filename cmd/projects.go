@@ -0,0 +1,212 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// Project is an entry of the 'projects' list in the localizely.yml file.
+// Its settings override the top-level settings of the config file for that project.
+type Project struct {
+	Name     string
+	Settings map[string]interface{}
+}
+
+// forEachProject calls fn for every project selected with the project flag (all projects by default), with the project settings applied to the config.
+// If the config file has no 'projects' list, fn is called once with an empty project name and the config is left as is.
+// Flags and environment variables take precedence over the project settings, in the same way as over the rest of the config file.
+func forEachProject(fn func(project string) error) error {
+	selected := viper.GetString("project")
+
+	if err := checkConfigFile(); err != nil {
+		return err
+	}
+
+	settings, err := readConfigFileSettings()
+	if err != nil {
+		return err
+	}
+
+	projects, err := readProjects(settings)
+	if err != nil {
+		return err
+	}
+
+	if len(projects) == 0 {
+		if selected != "" {
+			return newConfigError(fmt.Sprintf("The project '%s' was selected, but the %s file has no 'projects' list.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", selected, LocalizelyYamlFile))
+		}
+		return fn("")
+	}
+
+	// The config file settings are replaced for each project, so they must be restored afterwards
+	defer loadConfigSettings(settings)
+
+	found := false
+	for _, p := range projects {
+		if selected != "" && p.Name != selected {
+			continue
+		}
+		found = true
+
+		base := map[string]interface{}{}
+		for k, v := range settings {
+			if k != "projects" {
+				base[k] = v
+			}
+		}
+		if err := loadConfigSettings(mergeSettings(base, p.Settings)); err != nil {
+			return err
+		}
+
+		if err := fn(p.Name); err != nil {
+			return fmt.Errorf("Project '%s': %w", p.Name, err)
+		}
+	}
+
+	if !found {
+		var names []string
+		for _, p := range projects {
+			names = append(names, p.Name)
+		}
+		return newConfigError(fmt.Sprintf("The project '%s' is not configured in the %s file.\n\nAvailable projects:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", selected, LocalizelyYamlFile, formatOptions(names, 1, "unordered")))
+	}
+
+	return nil
+}
+
+// readConfigFileSettings reads the settings of the config file used by viper, as they are written in the file.
+func readConfigFileSettings() (map[string]interface{}, error) {
+	settings := map[string]interface{}{}
+
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return settings, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, newConfigError(fmt.Sprintf("Failed to read the '%s' file\nError: %v\n", path, err))
+	}
+
+	if err := yaml.Unmarshal(b, &settings); err != nil {
+		return nil, newConfigError(fmt.Sprintf("Failed to parse the '%s' file\nError: %v\n", path, err))
+	}
+	if settings == nil {
+		settings = map[string]interface{}{}
+	}
+
+	return settings, nil
+}
+
+func readProjects(settings map[string]interface{}) ([]Project, error) {
+	value, ok := settings["projects"]
+	if !ok || value == nil {
+		return nil, nil
+	}
+
+	list, ok := value.([]interface{})
+	if !ok {
+		return nil, newConfigError(fmt.Sprintf("The 'projects' key has invalid value.\n\nIt must be a list of projects, each with a unique 'name'.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n"))
+	}
+
+	var projects []Project
+	names := map[string]bool{}
+	for i, v := range list {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, newConfigError(fmt.Sprintf("The project entry #%d has invalid value.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", i+1))
+		}
+
+		name, _ := entry["name"].(string)
+		if strings.TrimSpace(name) == "" {
+			return nil, newConfigError(fmt.Sprintf("The project entry #%d is missing the 'name' key.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", i+1))
+		}
+		if names[name] {
+			return nil, newConfigError(fmt.Sprintf("The project name '%s' is used by more than one project entry.\n\nProject names must be unique.\n\n", name))
+		}
+		names[name] = true
+
+		projectSettings := map[string]interface{}{}
+		for k, v := range entry {
+			if k != "name" {
+				projectSettings[k] = v
+			}
+		}
+
+		projects = append(projects, Project{Name: name, Settings: projectSettings})
+	}
+
+	return projects, nil
+}
+
+// mergeSettings merges the override settings into the base settings. Nested maps are merged, while other values (including lists) are replaced.
+func mergeSettings(base map[string]interface{}, override map[string]interface{}) map[string]interface{} {
+	merged := map[string]interface{}{}
+	for k, v := range base {
+		merged[k] = v
+	}
+
+	for k, v := range override {
+		baseMap, baseIsMap := merged[k].(map[string]interface{})
+		overrideMap, overrideIsMap := v.(map[string]interface{})
+		if baseIsMap && overrideIsMap {
+			merged[k] = mergeSettings(baseMap, overrideMap)
+		} else {
+			merged[k] = v
+		}
+	}
+
+	return merged
+}
+
+// loadConfigSettings replaces the config file settings of viper, keeping the flags, environment variables and defaults.
+func loadConfigSettings(settings map[string]interface{}) error {
+	b, err := yaml.Marshal(settings)
+	if err != nil {
+		return err
+	}
+
+	return viper.ReadConfig(bytes.NewReader(b))
+}
+
+// printProjectHeader prints the name of the project before its output, if the config file has multiple projects.
+func printProjectHeader(project string, first bool) {
+	if project == "" || isStructuredOutput() {
+		return
+	}
+	if !first {
+		fmt.Println()
+	}
+	fmt.Printf("Project: %s\n", project)
+}
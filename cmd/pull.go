@@ -28,9 +28,9 @@ import (
 	"io"
 	"os"
 	"path/filepath"
-	"reflect"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/fatih/color"
 	"github.com/localizely/localizely-client-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,125 +39,429 @@ import (
 var pullCmd = &cobra.Command{
 	Use:     "pull",
 	Short:   "Pull localization files from Localizely",
-	Example: "  localizely-cli pull \\\n    --api-token 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef \\\n    --project-id 01234567-abcd-abcd-abcd-0123456789ab \\\n    --file-type json \\\n    --files \"file[0]=lang/en.json\",\"locale_code[0]=en\",\"file[1]=lang/de_DE.json\",\"locale_code[1]=de-DE\" \\\n    --export-empty-as empty \\\n    --include-tags new,updated \\\n    --exclude-tags removed",
+	Example: "  localizely-cli pull \\\n    --api-token 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef \\\n    --project-id 01234567-abcd-abcd-abcd-0123456789ab \\\n    --file-type json \\\n    --files \"file[0]=lang/en.json\",\"locale_code[0]=en\",\"file[1]=lang/de_DE.json\",\"locale_code[1]=de-DE\" \\\n    --export-empty-as empty \\\n    --include-tags new,updated \\\n    --exclude-tags removed \\\n    --concurrency 4",
 	PreRun: func(cmd *cobra.Command, args []string) {
-		// Bind flags only if the command is executed (fixes issue with global viper and the same flag names in multiple cobra commands)
-		// More info: https://github.com/spf13/viper/issues/233#issuecomment-386791444
-		viper.BindPFlag("api_token", cmd.Flags().Lookup("api-token"))
-		viper.BindPFlag("project_id", cmd.Flags().Lookup("project-id"))
-		viper.BindPFlag("branch", cmd.Flags().Lookup("branch"))
-		viper.BindPFlag("file_type", cmd.Flags().Lookup("file-type"))
-		viper.BindPFlag("download.files", cmd.Flags().Lookup("files"))
-		viper.BindPFlag("download.params.java_properties_encoding", cmd.Flags().Lookup("java-properties-encoding"))
-		viper.BindPFlag("download.params.export_empty_as", cmd.Flags().Lookup("export-empty-as"))
-		viper.BindPFlag("download.params.include_tags", cmd.Flags().Lookup("include-tags"))
-		viper.BindPFlag("download.params.exclude_tags", cmd.Flags().Lookup("exclude-tags"))
+		bindDownloadFlags(cmd)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		apiToken := viper.GetString("api_token")
-		projectId := viper.GetString("project_id")
-		branch := viper.GetString("branch")
-		fileType := viper.GetString("file_type")
-		javaPropertiesEncoding := viper.GetString("download.params.java_properties_encoding")
-		files := viper.Get("download.files")
-		exportEmptyAs := viper.GetString("download.params.export_empty_as")
-		includeTags := viper.GetStringSlice("download.params.include_tags")
-		excludeTags := viper.GetStringSlice("download.params.exclude_tags")
-
-		localizationFiles := []LocalizationFile{}
-		if reflect.TypeOf(files).String() == "[]interface {}" {
-			convertFilesConfigToLocalizationFiles(files.([]interface{}), &localizationFiles)
-		} else if reflect.TypeOf(files).String() == "map[string]interface {}" {
-			convertFilesFlagToLocalizationFiles(files.(map[string]interface{}), &localizationFiles)
-		}
-
-		err := validateApiToken(apiToken)
+		configs, err := readPullConfigs(cmd.Context())
 		checkError(err)
 
-		err = validateProjectId(projectId)
+		dryRun, err := cmd.Flags().GetBool("dry-run")
 		checkError(err)
 
-		err = validateFileType(fileType)
-		checkError(err)
-
-		err = validateFiles(localizationFiles, "pull")
-		checkError(err)
-
-		err = validateExportEmptyAs(exportEmptyAs)
-		checkError(err)
+		if dryRun {
+			for i, config := range configs {
+				printProjectHeader(config.Project, i == 0)
+				reportPlan(config.Project, config.Files, func() { printPullPlan(config) })
+			}
 
-		err = validateJavaPropertiesEncoding(javaPropertiesEncoding)
-		checkError(err)
+			// The plan is the whole text output of a dry run
+			if isStructuredOutput() {
+				reportSuccess("Dry run, no files were pulled")
+			}
+			return
+		}
 
-		err = pullLocalizationFiles(apiToken, projectId, branch, fileType, javaPropertiesEncoding, localizationFiles, exportEmptyAs, includeTags, excludeTags)
+		// Nothing is written unless all files of all projects are pulled successfully
+		results, err := pullLocalizationFiles(cmd.Context(), configs)
+		action := "Pulled"
+		if err != nil {
+			action = "Aborted"
+		}
+		for i, v := range results {
+			printProjectHeader(configs[i].Project, i == 0)
+			reportFileResults(configs[i].Project, v, action)
+		}
 		checkError(err)
 
-		color.Green("Successfully pulled data from Localizely")
+		reportSuccess("Successfully pulled data from Localizely")
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(pullCmd)
 
-	pullCmd.Flags().String("api-token", "", "API token\nYour API token from https://app.localizely.com/account")
-	pullCmd.Flags().String("project-id", "", "Project ID\nYour project ID from https://app.localizely.com/projects")
-	pullCmd.Flags().String("branch", "", "Branch name\nBranch in Localizely project to sync files with")
-	pullCmd.Flags().StringToString("files", map[string]string{}, "List of localization files to pull from Localizely\nExample:\n\t--files \"file[0]=lang/en_US.json\",\"locale_code[0]=en-US\"")
-	pullCmd.Flags().String("file-type", "", "File type\n"+formatOptions(fileTypesOpt, 2, "unordered"))
-	pullCmd.Flags().String("java-properties-encoding", "", "Character encoding for java_properties file type (default \"latin_1\")\n"+formatOptions(javaPropertiesEncodingOpt, 1, "unordered"))
-	pullCmd.Flags().String("export-empty-as", "", "Export empty translations as (default \"empty\")\n"+formatOptions(exportEmptyAsOpt, 1, "unordered"))
-	pullCmd.Flags().StringSlice("include-tags", []string{}, "List of tags to include in pull\nIf not set, all string keys will be considered for download")
-	pullCmd.Flags().StringSlice("exclude-tags", []string{}, "List of tags to exclude from pull\nIf not set, all string keys will be considered for download")
+	addDownloadFlags(pullCmd)
+	pullCmd.Flags().Bool("dry-run", false, "Validate the configuration and print the files that would be pulled, without downloading or writing them")
 }
 
-func pullLocalizationFiles(apiToken string, projectId string, branch string, fileType string, javaPropertiesEncoding string, files []LocalizationFile, exportEmptyAs string, includeTags []string, excludeTags []string) error {
-	cfg := localizely.NewConfiguration()
-	apiClient := localizely.NewAPIClient(cfg)
-	ctx := context.WithValue(context.Background(), localizely.ContextAPIKeys, map[string]localizely.APIKey{"API auth": {Key: apiToken}})
+// addDownloadFlags adds the flags shared by all commands that download localization files from Localizely.
+func addDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().String("api-token", "", "API token\nYour API token from https://app.localizely.com/account")
+	cmd.Flags().String("api-token-file", "", "Path to a file containing the API token\nUsed if the API token is not set otherwise")
+	cmd.Flags().String("project", "", "Name of the project from the projects list of the localizely.yml file\nIf not set, all projects are used")
+	cmd.Flags().String("project-id", "", "Project ID\nYour project ID from https://app.localizely.com/projects")
+	cmd.Flags().String("branch", "", "Branch name\nBranch in Localizely project to sync files with")
+	addRetryFlags(cmd)
+	cmd.Flags().StringToString("files", map[string]string{}, "List of localization files to pull from Localizely\nExample:\n\t--files \"file[0]=lang/en_US.json\",\"locale_code[0]=en-US\"")
+	cmd.Flags().String("file-type", "", "File type\n"+formatOptions(fileTypesOpt, 2, "unordered"))
+	cmd.Flags().String("java-properties-encoding", "", "Character encoding for java_properties file type (default \"latin_1\")\n"+formatOptions(javaPropertiesEncodingOpt, 1, "unordered"))
+	cmd.Flags().String("export-empty-as", "", "Export empty translations as (default \"empty\")\n"+formatOptions(exportEmptyAsOpt, 1, "unordered"))
+	cmd.Flags().StringSlice("include-tags", []string{}, "List of tags to include in pull\nIf not set, all string keys will be considered for download")
+	cmd.Flags().StringSlice("exclude-tags", []string{}, "List of tags to exclude from pull\nIf not set, all string keys will be considered for download")
+	cmd.Flags().Int("concurrency", 1, "Number of localization files to download in parallel")
+	cmd.Flags().Bool("all-languages", false, "Download all languages of the project\nLanguages that are not listed in the files are saved to the path derived from the file pattern")
+	cmd.Flags().String("file-pattern", "", "File path pattern for languages that are not listed in the files (used with all-languages)\nExample:\n\t--file-pattern \"lang/{locale_underscore}.json\"")
+}
 
-	for _, v := range files {
-		req := apiClient.DownloadAPIAPI.GetLocalizationFile(ctx, projectId)
-		req = req.LangCodes(v.LocaleCode)
-		req = req.Type_(fileType)
-		if branch != "" {
-			req = req.Branch(branch)
+// downloadConfigFlags are the config keys that can be set through the flags of the commands that download localization files.
+var downloadConfigFlags = []ConfigFlag{
+	{"api_token", "api-token"},
+	{"api_token_file", "api-token-file"},
+	{"project", "project"},
+	{"project_id", "project-id"},
+	{"branch", "branch"},
+	{"max_retries", "max-retries"},
+	{"retry_timeout", "retry-timeout"},
+	{"file_type", "file-type"},
+	{"download.files", "files"},
+	{"download.params.java_properties_encoding", "java-properties-encoding"},
+	{"download.params.export_empty_as", "export-empty-as"},
+	{"download.params.include_tags", "include-tags"},
+	{"download.params.exclude_tags", "exclude-tags"},
+	{"download.params.concurrency", "concurrency"},
+	{"download.all_languages", "all-languages"},
+	{"download.file_pattern", "file-pattern"},
+}
+
+func bindDownloadFlags(cmd *cobra.Command) {
+	bindConfigFlags(cmd, downloadConfigFlags)
+}
+
+type PullConfig struct {
+	Project                string
+	ApiToken               string
+	ProjectId              string
+	Branch                 string
+	FileType               string
+	JavaPropertiesEncoding string
+	Files                  []LocalizationFile
+	AllLanguages           bool
+	FilePattern            string
+	ExportEmptyAs          string
+	IncludeTags            []string
+	ExcludeTags            []string
+	Concurrency            int
+	RetryPolicy            RetryPolicy
+	// ApiClient is created with the settings of the project, e.g. its api_url and TLS settings
+	ApiClient *localizely.APIClient
+}
+
+// readPullConfigs reads and validates the pull config of every selected project.
+func readPullConfigs(ctx context.Context) ([]PullConfig, error) {
+	var configs []PullConfig
+
+	err := forEachProject(func(project string) error {
+		config, err := readPullConfig(ctx, false)
+		if err != nil {
+			return err
+		}
+		config.Project = project
+
+		if err := validatePullConfig(config); err != nil {
+			return err
 		}
-		if len(includeTags) > 0 {
-			req = req.IncludeTags(includeTags)
+
+		configs = append(configs, config)
+		return nil
+	})
+
+	return configs, err
+}
+
+// readPullConfig reads the pull config of the current project. If local is set, the project languages are not fetched from the Localizely API.
+func readPullConfig(ctx context.Context, local bool) (PullConfig, error) {
+	config := PullConfig{
+		ProjectId:              viper.GetString("project_id"),
+		Branch:                 viper.GetString("branch"),
+		FileType:               viper.GetString("file_type"),
+		JavaPropertiesEncoding: viper.GetString("download.params.java_properties_encoding"),
+		AllLanguages:           viper.GetBool("download.all_languages"),
+		FilePattern:            configPath("download.file_pattern"),
+		ExportEmptyAs:          viper.GetString("download.params.export_empty_as"),
+		IncludeTags:            viper.GetStringSlice("download.params.include_tags"),
+		ExcludeTags:            viper.GetStringSlice("download.params.exclude_tags"),
+		Concurrency:            viper.GetInt("download.params.concurrency"),
+		RetryPolicy:            readRetryPolicy(),
+	}
+
+	apiToken, _, err := readApiToken()
+	if err != nil {
+		return config, err
+	}
+	config.ApiToken = apiToken
+
+	apiClient, err := newApiClient()
+	if err != nil {
+		return config, err
+	}
+	config.ApiClient = apiClient
+
+	projectLocales := localProjectLocales
+	if !local {
+		projectLocales = projectLocalesFetcher(ctx, config.ApiToken, config.ProjectId, config.Branch, config.RetryPolicy)
+	}
+
+	files, err := readLocalizationFiles("download.files", projectLocales)
+	if err != nil {
+		return config, err
+	}
+
+	if config.AllLanguages {
+		files, err = addProjectLanguageFiles(files, config.FilePattern, projectLocales)
+		if err != nil {
+			return config, err
 		}
-		if len(excludeTags) > 0 {
-			req = req.ExcludeTags(excludeTags)
+	}
+	config.Files = withDefaultFileParams(files, config.FileType, config.JavaPropertiesEncoding)
+
+	return config, nil
+}
+
+// withDefaultFileParams sets the global file type and java properties encoding on the files that do not override them.
+func withDefaultFileParams(files []LocalizationFile, fileType string, javaPropertiesEncoding string) []LocalizationFile {
+	for i := range files {
+		if files[i].FileType == "" {
+			files[i].FileType = fileType
 		}
-		if exportEmptyAs != "" {
-			req = req.ExportEmptyAs(exportEmptyAs)
+		if files[i].JavaPropertiesEncoding == "" {
+			files[i].JavaPropertiesEncoding = javaPropertiesEncoding
 		}
-		if javaPropertiesEncoding != "" {
-			req = req.JavaPropertiesEncoding(javaPropertiesEncoding)
+	}
+
+	return files
+}
+
+// addProjectLanguageFiles adds a localization file for every project language that is not already listed in the files.
+// The path of the added files is derived from the file pattern.
+func addProjectLanguageFiles(files []LocalizationFile, filePattern string, projectLocales func() ([]string, error)) ([]LocalizationFile, error) {
+	if err := validateFilePattern(filePattern); err != nil {
+		return nil, err
+	}
+
+	localeCodes, err := projectLocales()
+	if err != nil {
+		return nil, err
+	}
+
+	listed := map[string]bool{}
+	for _, v := range files {
+		listed[normalizeLocaleCode(v.LocaleCode)] = true
+	}
+
+	for _, localeCode := range localeCodes {
+		if listed[normalizeLocaleCode(localeCode)] {
+			continue
 		}
 
-		resp, err := req.Execute()
-		if err != nil {
-			b, _ := io.ReadAll(resp.Body)
-			jsonErr := string(b)
-			return errors.New(fmt.Sprintf("Failed to pull data from Localizely\nError: %v\n%s\n", err, jsonErr))
+		files = append(files, LocalizationFile{
+			File:       expandLocalePlaceholders(filePattern, localeCode),
+			LocaleCode: localeCode,
+		})
+	}
+
+	return files, nil
+}
+
+func validatePullConfig(config PullConfig) error {
+	if err := validateApiToken(config.ApiToken); err != nil {
+		return err
+	}
+
+	if err := validateProjectId(config.ProjectId); err != nil {
+		return err
+	}
+
+	if err := validateFiles(config.Files, "pull"); err != nil {
+		return err
+	}
+
+	if err := validateFileParams(config.Files); err != nil {
+		return err
+	}
+
+	if err := validateExportEmptyAs(config.ExportEmptyAs); err != nil {
+		return err
+	}
+
+	if err := validateConcurrency(config.Concurrency); err != nil {
+		return err
+	}
+
+	return validateRetryPolicy(config.RetryPolicy)
+}
+
+// validateFileParams validates the file type and java properties encoding of every file, after the global defaults are applied.
+func validateFileParams(files []LocalizationFile) error {
+	for _, v := range files {
+		if err := validateFileType(v.FileType); err != nil {
+			return newConfigError(fmt.Sprintf("Invalid file type of localization file '%s'\n\n%v", filepath.Clean(v.File), err))
+		}
+
+		if err := validateJavaPropertiesEncoding(v.JavaPropertiesEncoding); err != nil {
+			return newConfigError(fmt.Sprintf("Invalid java properties encoding of localization file '%s'\n\n%v", filepath.Clean(v.File), err))
+		}
+	}
+
+	return nil
+}
+
+func printPullPlan(config PullConfig) {
+	fmt.Printf("Dry run: nothing will be downloaded from Localizely or written to disk\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Project ID:\t%s\n", config.ProjectId)
+	fmt.Fprintf(w, "Branch:\t%s\n", formatPlanValue(config.Branch))
+	fmt.Fprintf(w, "File type:\t%s\n", formatPlanValue(config.FileType))
+	if config.AllLanguages {
+		fmt.Fprintf(w, "File pattern:\t%s\n", config.FilePattern)
+	}
+	fmt.Fprintf(w, "Export empty as:\t%s\n", formatPlanValue(config.ExportEmptyAs))
+	fmt.Fprintf(w, "Include tags:\t%s\n", formatPlanValue(strings.Join(config.IncludeTags, ", ")))
+	fmt.Fprintf(w, "Exclude tags:\t%s\n", formatPlanValue(strings.Join(config.ExcludeTags, ", ")))
+	fmt.Fprintf(w, "Concurrency:\t%d\n", config.Concurrency)
+	w.Flush()
+
+	fmt.Printf("\nFiles to pull (%d):\n", len(config.Files))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, v := range config.Files {
+		fileType := v.FileType
+		if v.FileType == "java_properties" {
+			fileType += ", " + formatPlanValue(v.JavaPropertiesEncoding)
 		}
-		defer resp.Body.Close()
+		fmt.Fprintf(w, "  %s\t->\t%s\t(%s)\n", v.LocaleCode, filepath.Clean(v.File), fileType)
+	}
+	w.Flush()
+}
+
+// pullLocalizationFiles downloads the files of all projects before writing any of them, so a failure (or an interrupt) leaves the local files untouched.
+// The results are returned per project, for the projects that were downloaded up to the failure.
+func pullLocalizationFiles(ctx context.Context, configs []PullConfig) ([][]FileResult, error) {
+	tx := newFileTransaction()
+	results := [][]FileResult{}
+	hashes := make([][]string, len(configs))
+
+	for i, config := range configs {
+		projectResults, projectHashes, err := stageLocalizationFiles(ctx, config, tx)
+		results = append(results, projectResults)
+		hashes[i] = projectHashes
 
-		b, err := io.ReadAll(resp.Body)
 		if err != nil {
-			return errors.New(fmt.Sprintf("Failed to read response from the server\nError: %v\n", err))
+			tx.Rollback()
+
+			prefix := ""
+			if config.Project != "" {
+				prefix = fmt.Sprintf("Project '%s': ", config.Project)
+			}
+
+			// Files are only written if all downloads succeed, so the failure is never partial
+			var fileErrs *FileErrors
+			if errors.As(err, &fileErrs) {
+				return results, &FileErrors{Message: fmt.Sprintf("%s%vNo local files were changed\n", prefix, err), Errs: fileErrs.Errs}
+			}
+			return results, fmt.Errorf("%s%w", prefix, err)
+		}
+	}
+
+	if ctx.Err() != nil {
+		tx.Rollback()
+		return results, errors.New("Pull was cancelled\nNo local files were changed\n")
+	}
+
+	if err := tx.Commit(); err != nil {
+		return results, err
+	}
+
+	saveSyncState(func(state *SyncState) {
+		for i, config := range configs {
+			for j, v := range config.Files {
+				state.RecordPull(v, newSyncStateEntry(hashes[i][j], config.ProjectId, config.Branch))
+			}
 		}
+	})
+
+	return results, nil
+}
 
-		err = os.MkdirAll(filepath.Dir(v.File), 0777)
+// stageLocalizationFiles downloads the files of the project and stages them in the transaction, and returns the hashes of their content.
+func stageLocalizationFiles(ctx context.Context, config PullConfig, tx *fileTransaction) ([]FileResult, []string, error) {
+	ctx = newApiContext(ctx, config.ApiToken)
+	hashes := make([]string, len(config.Files))
+	sizes := make([]int64, len(config.Files))
+	httpStatuses := make([]int, len(config.Files))
+
+	results := runFileTasks(config.Files, config.Concurrency, func(i int, v LocalizationFile) error {
+		b, httpStatus, err := downloadLocalizationFile(ctx, config.ApiClient, config, v)
 		if err != nil {
-			return errors.New(fmt.Sprintf("Failed to create directory '%s'\nError: %v\n", filepath.Dir(v.File), err))
+			return err
 		}
+		hashes[i] = hashContent(b)
+		sizes[i] = int64(len(b))
+		httpStatuses[i] = httpStatus
 
-		err = os.WriteFile(filepath.Clean(v.File), b, 0666)
+		err = validateLocalizationFileContent(v.FileType, v, b)
 		if err != nil {
-			return errors.New(fmt.Sprintf("Failed to save localization file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+			return err
+		}
+
+		return tx.Stage(filepath.Clean(v.File), b)
+	})
+
+	for i := range results {
+		if results[i].Err == nil {
+			results[i].Bytes = sizes[i]
+			results[i].HttpStatus = httpStatuses[i]
 		}
 	}
 
+	return results, hashes, joinFileErrors(results)
+}
+
+// validateLocalizationFileContent checks that the downloaded content can be parsed, for the file types that support parsing.
+func validateLocalizationFileContent(fileType string, file LocalizationFile, data []byte) error {
+	if !supportsKeyParsing(fileType) {
+		return nil
+	}
+
+	if _, err := parseLocalizationFile(fileType, data); err != nil {
+		return errors.New(fmt.Sprintf("Invalid content of localization file '%s' downloaded from Localizely\nError: %v\n", filepath.Clean(file.File), err))
+	}
+
 	return nil
 }
+
+// downloadLocalizationFile downloads the content of the localization file from Localizely into memory, and returns it with the HTTP status.
+func downloadLocalizationFile(ctx context.Context, apiClient *localizely.APIClient, config PullConfig, file LocalizationFile) ([]byte, int, error) {
+	req := apiClient.DownloadAPIAPI.GetLocalizationFile(ctx, config.ProjectId)
+	req = req.LangCodes(file.LocaleCode)
+	req = req.Type_(file.FileType)
+	if config.Branch != "" {
+		req = req.Branch(config.Branch)
+	}
+	if len(config.IncludeTags) > 0 {
+		req = req.IncludeTags(config.IncludeTags)
+	}
+	if len(config.ExcludeTags) > 0 {
+		req = req.ExcludeTags(config.ExcludeTags)
+	}
+	if config.ExportEmptyAs != "" {
+		req = req.ExportEmptyAs(config.ExportEmptyAs)
+	}
+	if file.JavaPropertiesEncoding != "" {
+		req = req.JavaPropertiesEncoding(file.JavaPropertiesEncoding)
+	}
+
+	resp, err := executeWithRetry(ctx, config.RetryPolicy, true, req.Execute)
+	if err != nil {
+		return nil, 0, newApiError(fmt.Sprintf("Failed to pull localization file '%s' from Localizely\nError: %v\n%s\n", filepath.Clean(file.File), err, readResponseBody(resp)), resp, err)
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, newApiError(fmt.Sprintf("Failed to read response from the server\nError: %v\n", err), nil, err)
+	}
+
+	return b, resp.StatusCode, nil
+}
@@ -25,12 +25,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
+	"net/http"
 	"os"
 	"path/filepath"
-	"reflect"
+	"strings"
+	"text/tabwriter"
 
-	"github.com/fatih/color"
 	"github.com/localizely/localizely-client-go"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -39,112 +39,357 @@ import (
 var pushCmd = &cobra.Command{
 	Use:     "push",
 	Short:   "Push localization files to Localizely",
-	Example: "  localizely-cli push \\\n    --api-token 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef \\\n    --project-id 01234567-abcd-abcd-abcd-0123456789ab \\\n    --files \"file[0]=lang/en.json\",\"locale_code[0]=en\",\"file[1]=lang/de_DE.json\",\"locale_code[1]=de-DE\" \\\n    --overwrite \\\n    --reviewed=false \\\n    --tag-added new,new-feat-x \\\n    --tag-updated updated,updated-feat-x \\\n    --tag-removed removed",
+	Example: "  localizely-cli push \\\n    --api-token 0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef \\\n    --project-id 01234567-abcd-abcd-abcd-0123456789ab \\\n    --files \"file[0]=lang/en.json\",\"locale_code[0]=en\",\"file[1]=lang/de_DE.json\",\"locale_code[1]=de-DE\" \\\n    --overwrite \\\n    --reviewed=false \\\n    --tag-added new,new-feat-x \\\n    --tag-updated updated,updated-feat-x \\\n    --tag-removed removed \\\n    --concurrency 4",
 	PreRun: func(cmd *cobra.Command, args []string) {
-		// Bind flags only if the command is executed (fixes issue with global viper and the same flag names in multiple cobra commands)
-		// More info: https://github.com/spf13/viper/issues/233#issuecomment-386791444
-		viper.BindPFlag("api_token", cmd.Flags().Lookup("api-token"))
-		viper.BindPFlag("project_id", cmd.Flags().Lookup("project-id"))
-		viper.BindPFlag("branch", cmd.Flags().Lookup("branch"))
-		viper.BindPFlag("upload.files", cmd.Flags().Lookup("files"))
-		viper.BindPFlag("upload.params.overwrite", cmd.Flags().Lookup("overwrite"))
-		viper.BindPFlag("upload.params.reviewed", cmd.Flags().Lookup("reviewed"))
-		viper.BindPFlag("upload.params.tag_added", cmd.Flags().Lookup("tag-added"))
-		viper.BindPFlag("upload.params.tag_updated", cmd.Flags().Lookup("tag-updated"))
-		viper.BindPFlag("upload.params.tag_removed", cmd.Flags().Lookup("tag-removed"))
+		bindUploadFlags(cmd)
 	},
 	Run: func(cmd *cobra.Command, args []string) {
-		apiToken := viper.GetString("api_token")
-		projectId := viper.GetString("project_id")
-		branch := viper.GetString("branch")
-		files := viper.Get("upload.files")
-		overwrite := viper.GetBool("upload.params.overwrite")
-		reviewed := viper.GetBool("upload.params.reviewed")
-		tagAdded := viper.GetStringSlice("upload.params.tag_added")
-		tagUpdated := viper.GetStringSlice("upload.params.tag_updated")
-		tagRemoved := viper.GetStringSlice("upload.params.tag_removed")
-
-		localizationFiles := []LocalizationFile{}
-		if reflect.TypeOf(files).String() == "[]interface {}" {
-			convertFilesConfigToLocalizationFiles(files.([]interface{}), &localizationFiles)
-		} else if reflect.TypeOf(files).String() == "map[string]interface {}" {
-			convertFilesFlagToLocalizationFiles(files.(map[string]interface{}), &localizationFiles)
-		}
-
-		err := validateApiToken(apiToken)
+		configs, err := readPushConfigs(cmd.Context())
 		checkError(err)
 
-		err = validateProjectId(projectId)
+		dryRun, err := cmd.Flags().GetBool("dry-run")
 		checkError(err)
 
-		err = validateFiles(localizationFiles, "push")
-		checkError(err)
+		// A failed project does not stop the other projects, as they are pushed independently
+		var projects []string
+		var results [][]FileResult
+		var errs []error
+		for i, config := range configs {
+			printProjectHeader(config.Project, i == 0)
 
-		err = pushLocalizationFiles(apiToken, projectId, branch, localizationFiles, overwrite, reviewed, tagAdded, tagUpdated, tagRemoved)
-		checkError(err)
+			if dryRun {
+				reportPlan(config.Project, config.Files, func() { printPushPlan(config) })
+				continue
+			}
+
+			projectResults, err := pushLocalizationFiles(cmd.Context(), config)
+			reportFileResults(config.Project, projectResults, "Pushed")
 
-		color.Green("Successfully pushed data to Localizely")
+			projects = append(projects, config.Project)
+			results = append(results, projectResults)
+			errs = append(errs, err)
+
+			if cmd.Context().Err() != nil {
+				break
+			}
+		}
+		checkError(joinProjectErrors(projects, results, errs))
+
+		if dryRun {
+			// The plan is the whole text output of a dry run
+			if isStructuredOutput() {
+				reportSuccess("Dry run, no files were pushed")
+			}
+		} else {
+			reportSuccess("Successfully pushed data to Localizely")
+		}
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(pushCmd)
 
-	pushCmd.Flags().String("api-token", "", "API token\nYour API token from https://app.localizely.com/account")
-	pushCmd.Flags().String("project-id", "", "Project ID\nYour project ID from https://app.localizely.com/projects")
-	pushCmd.Flags().String("branch", "", "Branch name\nBranch in Localizely project to sync files with")
-	pushCmd.Flags().StringToString("files", map[string]string{}, "List of localization files to push to Localizely\nExample:\n\t--files \"file[0]=lang/en_US.json\",\"locale_code[0]=en-US\"")
-	pushCmd.Flags().Bool("overwrite", false, "Overwrite translations\nIf the translation in a given language should be overwritten with modified translation from uploading file")
-	pushCmd.Flags().Bool("reviewed", false, "Mark translations as reviewed\nIf uploading translations, that are added, should be marked as Reviewed\nFor uploading translations that are only modified it will have effect only if overwrite is set to true")
-	pushCmd.Flags().StringSlice("tag-added", []string{}, "List of tags to add to new translations from uploading file")
-	pushCmd.Flags().StringSlice("tag-updated", []string{}, "List of tags to add to updated translations from uploading file")
-	pushCmd.Flags().StringSlice("tag-removed", []string{}, "List of tags to add to removed translations from uploading file")
+	addUploadFlags(pushCmd)
+	pushCmd.Flags().Int("concurrency", 1, "Number of localization files to push in parallel")
+	pushCmd.Flags().Bool("skip-unchanged", false, "Skip files whose content has not changed since the last push to the same project and branch")
+	pushCmd.Flags().Bool("dry-run", false, "Validate the configuration and print the files that would be pushed, without pushing them")
+}
+
+// addUploadFlags adds the flags shared by all commands that upload localization files to Localizely.
+func addUploadFlags(cmd *cobra.Command) {
+	cmd.Flags().String("api-token", "", "API token\nYour API token from https://app.localizely.com/account")
+	cmd.Flags().String("api-token-file", "", "Path to a file containing the API token\nUsed if the API token is not set otherwise")
+	cmd.Flags().String("project", "", "Name of the project from the projects list of the localizely.yml file\nIf not set, all projects are used")
+	cmd.Flags().String("project-id", "", "Project ID\nYour project ID from https://app.localizely.com/projects")
+	cmd.Flags().String("branch", "", "Branch name\nBranch in Localizely project to sync files with")
+	addRetryFlags(cmd)
+	cmd.Flags().StringToString("files", map[string]string{}, "List of localization files to push to Localizely\nExample:\n\t--files \"file[0]=lang/en_US.json\",\"locale_code[0]=en-US\"")
+	cmd.Flags().Bool("overwrite", false, "Overwrite translations\nIf the translation in a given language should be overwritten with modified translation from uploading file")
+	cmd.Flags().Bool("reviewed", false, "Mark translations as reviewed\nIf uploading translations, that are added, should be marked as Reviewed\nFor uploading translations that are only modified it will have effect only if overwrite is set to true")
+	cmd.Flags().StringSlice("tag-added", []string{}, "List of tags to add to new translations from uploading file")
+	cmd.Flags().StringSlice("tag-updated", []string{}, "List of tags to add to updated translations from uploading file")
+	cmd.Flags().StringSlice("tag-removed", []string{}, "List of tags to add to removed translations from uploading file")
+}
+
+// uploadConfigFlags are the config keys that can be set through the flags of the commands that upload localization files.
+var uploadConfigFlags = []ConfigFlag{
+	{"api_token", "api-token"},
+	{"api_token_file", "api-token-file"},
+	{"project", "project"},
+	{"project_id", "project-id"},
+	{"branch", "branch"},
+	{"max_retries", "max-retries"},
+	{"retry_timeout", "retry-timeout"},
+	{"upload.files", "files"},
+	{"upload.params.overwrite", "overwrite"},
+	{"upload.params.reviewed", "reviewed"},
+	{"upload.params.tag_added", "tag-added"},
+	{"upload.params.tag_updated", "tag-updated"},
+	{"upload.params.tag_removed", "tag-removed"},
+	{"upload.params.concurrency", "concurrency"},
+	{"upload.params.skip_unchanged", "skip-unchanged"},
+}
+
+func bindUploadFlags(cmd *cobra.Command) {
+	bindConfigFlags(cmd, uploadConfigFlags)
 }
 
-func pushLocalizationFiles(apiToken string, projectId string, branch string, files []LocalizationFile, overwrite bool, reviewed bool, tagAdded []string, tagUpdated []string, tagRemoved []string) error {
-	filesMap := make(map[string]*os.File)
-	for _, v := range files {
-		file, err := os.Open(filepath.Clean(v.File))
+type PushConfig struct {
+	Project       string
+	ApiToken      string
+	ProjectId     string
+	Branch        string
+	Files         []LocalizationFile
+	Overwrite     bool
+	Reviewed      bool
+	TagAdded      []string
+	TagUpdated    []string
+	TagRemoved    []string
+	Concurrency   int
+	SkipUnchanged bool
+	RetryPolicy   RetryPolicy
+	// ApiClient is created with the settings of the project, e.g. its api_url and TLS settings
+	ApiClient *localizely.APIClient
+}
+
+// readPushConfigs reads and validates the push config of every selected project.
+func readPushConfigs(ctx context.Context) ([]PushConfig, error) {
+	var configs []PushConfig
+
+	err := forEachProject(func(project string) error {
+		config, err := readPushConfig(ctx, false)
 		if err != nil {
-			return errors.New(fmt.Sprintf("Failed to open file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+			return err
 		}
-		defer file.Close()
-		filesMap[v.LocaleCode] = file
+		config.Project = project
+
+		if err := validatePushConfig(config); err != nil {
+			return err
+		}
+
+		configs = append(configs, config)
+		return nil
+	})
+
+	return configs, err
+}
+
+// readPushConfig reads the push config of the current project. If local is set, the project languages are not fetched from the Localizely API.
+func readPushConfig(ctx context.Context, local bool) (PushConfig, error) {
+	config := PushConfig{
+		ProjectId:     viper.GetString("project_id"),
+		Branch:        viper.GetString("branch"),
+		Overwrite:     viper.GetBool("upload.params.overwrite"),
+		Reviewed:      viper.GetBool("upload.params.reviewed"),
+		TagAdded:      viper.GetStringSlice("upload.params.tag_added"),
+		TagUpdated:    viper.GetStringSlice("upload.params.tag_updated"),
+		TagRemoved:    viper.GetStringSlice("upload.params.tag_removed"),
+		Concurrency:   viper.GetInt("upload.params.concurrency"),
+		SkipUnchanged: viper.GetBool("upload.params.skip_unchanged"),
+		RetryPolicy:   readRetryPolicy(),
+	}
+
+	apiToken, _, err := readApiToken()
+	if err != nil {
+		return config, err
+	}
+	config.ApiToken = apiToken
+
+	apiClient, err := newApiClient()
+	if err != nil {
+		return config, err
+	}
+	config.ApiClient = apiClient
+
+	projectLocales := localProjectLocales
+	if !local {
+		projectLocales = projectLocalesFetcher(ctx, config.ApiToken, config.ProjectId, config.Branch, config.RetryPolicy)
+	}
+
+	files, err := readLocalizationFiles("upload.files", projectLocales)
+	if err != nil {
+		return config, err
+	}
+	config.Files = files
+
+	return config, nil
+}
+
+func validatePushConfig(config PushConfig) error {
+	if err := validateApiToken(config.ApiToken); err != nil {
+		return err
+	}
+
+	if err := validateProjectId(config.ProjectId); err != nil {
+		return err
 	}
 
-	cfg := localizely.NewConfiguration()
-	apiClient := localizely.NewAPIClient(cfg)
-	ctx := context.WithValue(context.Background(), localizely.ContextAPIKeys, map[string]localizely.APIKey{"API auth": {Key: apiToken}})
+	if err := validateFiles(config.Files, "push"); err != nil {
+		return err
+	}
+
+	if err := validateFilesExist(config.Files); err != nil {
+		return err
+	}
+
+	if err := validateConcurrency(config.Concurrency); err != nil {
+		return err
+	}
+
+	return validateRetryPolicy(config.RetryPolicy)
+}
+
+func printPushPlan(config PushConfig) {
+	fmt.Printf("Dry run: nothing will be pushed to Localizely\n\n")
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Project ID:\t%s\n", config.ProjectId)
+	fmt.Fprintf(w, "Branch:\t%s\n", formatPlanValue(config.Branch))
+	fmt.Fprintf(w, "Overwrite:\t%t\n", config.Overwrite)
+	fmt.Fprintf(w, "Reviewed:\t%t\n", config.Reviewed)
+	fmt.Fprintf(w, "Tag added:\t%s\n", formatPlanValue(strings.Join(config.TagAdded, ", ")))
+	fmt.Fprintf(w, "Tag updated:\t%s\n", formatPlanValue(strings.Join(config.TagUpdated, ", ")))
+	fmt.Fprintf(w, "Tag removed:\t%s\n", formatPlanValue(strings.Join(config.TagRemoved, ", ")))
+	fmt.Fprintf(w, "Concurrency:\t%d\n", config.Concurrency)
+	fmt.Fprintf(w, "Skip unchanged:\t%t\n", config.SkipUnchanged)
+	w.Flush()
+
+	fmt.Printf("\nFiles to push (%d):\n", len(config.Files))
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, v := range config.Files {
+		fmt.Fprintf(w, "  %s\t->\t%s\n", filepath.Clean(v.File), v.LocaleCode)
+	}
+	w.Flush()
+}
 
-	for _, v := range files {
-		file := filesMap[v.LocaleCode]
+// joinProjectErrors combines the errors of the projects into one error, which is partial if any file of any project succeeded.
+func joinProjectErrors(projects []string, results [][]FileResult, errs []error) error {
+	var messages []string
+	var fileErrs []error
+	succeeded := false
 
-		req := apiClient.UploadAPIAPI.ImportLocalizationFile(ctx, projectId)
-		req = req.LangCode(v.LocaleCode)
-		req = req.File(file)
-		req = req.Overwrite(overwrite)
-		req = req.Reviewed(reviewed)
-		if branch != "" {
-			req = req.Branch(branch)
+	for i, err := range errs {
+		for _, v := range results[i] {
+			succeeded = succeeded || v.Err == nil
 		}
-		if len(tagAdded) > 0 {
-			req = req.TagAdded(tagAdded)
+
+		if err == nil {
+			continue
 		}
-		if len(tagUpdated) > 0 {
-			req = req.TagUpdated(tagUpdated)
+
+		prefix := ""
+		if projects[i] != "" {
+			prefix = fmt.Sprintf("Project '%s': ", projects[i])
 		}
-		if len(tagRemoved) > 0 {
-			req = req.TagRemoved(tagRemoved)
+		messages = append(messages, prefix+err.Error())
+
+		var projectErrs *FileErrors
+		if errors.As(err, &projectErrs) {
+			fileErrs = append(fileErrs, projectErrs.Errs...)
+		} else {
+			fileErrs = append(fileErrs, err)
 		}
+	}
+
+	if len(messages) == 0 {
+		return nil
+	}
 
-		resp, err := req.Execute()
+	return &FileErrors{
+		Message: strings.Join(messages, "\n"),
+		Errs:    fileErrs,
+		Partial: succeeded,
+	}
+}
+
+func pushLocalizationFiles(ctx context.Context, config PushConfig) ([]FileResult, error) {
+	// The state is only needed to skip unchanged files, otherwise a broken state file should not prevent the push
+	state, err := readSyncState()
+	if err != nil && config.SkipUnchanged {
+		return nil, errors.New(fmt.Sprintf("Failed to read the '%s' file\nError: %v\n", formatStateJsonFilePath(), err))
+	}
+
+	apiClient := config.ApiClient
+	ctx = newApiContext(ctx, config.ApiToken)
+	hashes := make([]string, len(config.Files))
+	skipped := make([]bool, len(config.Files))
+	sizes := make([]int64, len(config.Files))
+	httpStatuses := make([]int, len(config.Files))
+
+	results := runFileTasks(config.Files, config.Concurrency, func(i int, v LocalizationFile) error {
+		hash, err := hashFile(v.File)
 		if err != nil {
-			b, _ := io.ReadAll(resp.Body)
-			jsonErr := string(b)
-			return errors.New(fmt.Sprintf("Failed to push localization file '%s' to Localizely\nError: %v\n%s\n", file.Name(), err, jsonErr))
+			return errors.New(fmt.Sprintf("Failed to open file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+		}
+		hashes[i] = hash
+
+		if info, err := os.Stat(filepath.Clean(v.File)); err == nil {
+			sizes[i] = info.Size()
+		}
+
+		if config.SkipUnchanged {
+			if last := state.LastPush(v, config.ProjectId, config.Branch); last != nil && last.Hash == hash {
+				skipped[i] = true
+				return nil
+			}
+		}
+
+		// The client reads and closes the file on every execution, so it is reopened for each attempt
+		resp, err := executeWithRetry(ctx, config.RetryPolicy, false, func() (*http.Response, error) {
+			file, err := os.Open(filepath.Clean(v.File))
+			if err != nil {
+				return nil, err
+			}
+			defer file.Close()
+
+			req := apiClient.UploadAPIAPI.ImportLocalizationFile(ctx, config.ProjectId)
+			req = req.LangCode(v.LocaleCode)
+			req = req.File(file)
+			req = req.Overwrite(config.Overwrite)
+			req = req.Reviewed(config.Reviewed)
+			if config.Branch != "" {
+				req = req.Branch(config.Branch)
+			}
+			if len(config.TagAdded) > 0 {
+				req = req.TagAdded(config.TagAdded)
+			}
+			if len(config.TagUpdated) > 0 {
+				req = req.TagUpdated(config.TagUpdated)
+			}
+			if len(config.TagRemoved) > 0 {
+				req = req.TagRemoved(config.TagRemoved)
+			}
+
+			return req.Execute()
+		})
+		if err != nil {
+			return newApiError(fmt.Sprintf("Failed to push localization file '%s' to Localizely\nError: %v\n%s\n", filepath.Clean(v.File), err, readResponseBody(resp)), resp, err)
 		}
 		defer resp.Body.Close()
+		httpStatuses[i] = resp.StatusCode
+
+		return nil
+	})
+
+	pushed := false
+	for i := range results {
+		results[i].Skipped = skipped[i]
+		results[i].Bytes = sizes[i]
+		if results[i].Err == nil {
+			results[i].HttpStatus = httpStatuses[i]
+		}
+		pushed = pushed || (results[i].Err == nil && !skipped[i])
+	}
+
+	if pushed {
+		saveSyncState(func(state *SyncState) {
+			for i, v := range results {
+				if v.Err == nil && !v.Skipped {
+					state.RecordPush(v.File, newSyncStateEntry(hashes[i], config.ProjectId, config.Branch))
+				}
+			}
+		})
 	}
 
-	return nil
+	return results, joinFileErrors(results)
 }
@@ -0,0 +1,65 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestJoinProjectErrors(t *testing.T) {
+	ok := FileResult{File: LocalizationFile{File: "en.json", LocaleCode: "en"}}
+	failed := FileResult{File: LocalizationFile{File: "de.json", LocaleCode: "de"}, Err: errors.New("Failed to push")}
+	fileErrs := func(results ...FileResult) error {
+		return joinFileErrors(results)
+	}
+
+	tests := []struct {
+		name     string
+		projects []string
+		results  [][]FileResult
+		errs     []error
+		wantExit int
+	}{
+		{"all succeeded", []string{"a", "b"}, [][]FileResult{{ok}, {ok}}, []error{nil, nil}, 0},
+		{"failed project after a pushed project", []string{"a", "b"}, [][]FileResult{{ok}, {failed}}, []error{nil, fileErrs(failed)}, ExitCodePartialFailure},
+		{"failed project before a pushed project", []string{"a", "b"}, [][]FileResult{{failed}, {ok}}, []error{fileErrs(failed), nil}, ExitCodePartialFailure},
+		{"all projects failed", []string{"a", "b"}, [][]FileResult{{failed}, {failed}}, []error{fileErrs(failed), fileErrs(failed)}, ExitCodeError},
+		{"project failed before its files", []string{"a", "b"}, [][]FileResult{nil, {ok}}, []error{newConfigError("Failed to read the state"), nil}, ExitCodePartialFailure},
+		{"single project failed before its files", []string{""}, [][]FileResult{nil}, []error{newConfigError("Failed to read the state")}, ExitCodeConfig},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := joinProjectErrors(tt.projects, tt.results, tt.errs)
+			if tt.wantExit == 0 {
+				if err != nil {
+					t.Errorf("joinProjectErrors() = %v, want nil", err)
+				}
+				return
+			}
+			if got := exitCode(err); got != tt.wantExit {
+				t.Errorf("exitCode() = %d, want %d", got, tt.wantExit)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const retryBaseDelay = 500 * time.Millisecond
+
+const retryMaxDelay = 30 * time.Second
+
+type RetryPolicy struct {
+	MaxRetries int
+	Timeout    time.Duration
+}
+
+// addRetryFlags adds the flags of the retry policy, shared by all commands that call the Localizely API.
+func addRetryFlags(cmd *cobra.Command) {
+	cmd.Flags().Int("max-retries", 3, "Maximum number of retries for transient API failures (e.g. 429, 503, connection errors)")
+	cmd.Flags().Duration("retry-timeout", time.Minute, "Maximum total time to spend on retries of a single request (0 for no limit)")
+}
+
+// readRetryPolicy returns the retry policy set with the max_retries and retry_timeout keys.
+func readRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxRetries: viper.GetInt("max_retries"), Timeout: viper.GetDuration("retry_timeout")}
+}
+
+// executeWithRetry runs the request until it succeeds, fails with a non-retryable error, or the retry policy is exhausted.
+// The request is rebuilt on every attempt, so request bodies (e.g. uploaded files) must be reopened by the caller.
+// When idempotent is false, only failures where the server has certainly not processed the request are retried.
+func executeWithRetry(ctx context.Context, policy RetryPolicy, idempotent bool, execute func() (*http.Response, error)) (*http.Response, error) {
+	deadline := time.Now().Add(policy.Timeout)
+
+	for attempt := 0; ; attempt++ {
+		resp, err := execute()
+		// A cancelled or expired context fails the request with the same error as the request timeout of the client, which is retried
+		if err == nil || ctx.Err() != nil || attempt >= policy.MaxRetries || !isRetryable(resp, err, idempotent) {
+			return resp, err
+		}
+
+		delay := retryDelay(resp, attempt)
+		if policy.Timeout > 0 && time.Now().Add(delay).After(deadline) {
+			return resp, err
+		}
+
+		fmt.Fprintf(os.Stderr, "Request failed (%v), retrying in %v (attempt %d of %d)\n", err, delay.Round(time.Millisecond), attempt+1, policy.MaxRetries)
+
+		if resp != nil && resp.Body != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+func isRetryable(resp *http.Response, err error, idempotent bool) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusTooManyRequests, http.StatusServiceUnavailable:
+			return true
+		case http.StatusRequestTimeout, http.StatusInternalServerError, http.StatusBadGateway, http.StatusGatewayTimeout:
+			return idempotent
+		default:
+			return false
+		}
+	}
+
+	if idempotent {
+		return true
+	}
+
+	// The request was never sent if the connection could not be established
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "dial" {
+		return true
+	}
+
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// retryDelay honors the Retry-After header if present, and otherwise uses exponential backoff with jitter.
+func retryDelay(resp *http.Response, attempt int) time.Duration {
+	if resp != nil {
+		if retryAfter := resp.Header.Get("Retry-After"); retryAfter != "" {
+			if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+				return time.Duration(seconds) * time.Second
+			}
+			if t, err := http.ParseTime(retryAfter); err == nil {
+				return max(time.Until(t), 0)
+			}
+		}
+	}
+
+	backoff := retryMaxDelay
+	if attempt < 16 {
+		backoff = min(retryBaseDelay<<attempt, retryMaxDelay)
+	}
+
+	return backoff/2 + rand.N(backoff/2)
+}
@@ -0,0 +1,190 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestIsRetryable(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	readErr := &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}
+	dnsErr := &net.DNSError{Err: "no such host", Name: "api.localizely.com"}
+
+	tests := []struct {
+		name       string
+		status     int
+		err        error
+		idempotent bool
+		want       bool
+	}{
+		{"429 too many requests", http.StatusTooManyRequests, errors.New("429"), false, true},
+		{"503 service unavailable", http.StatusServiceUnavailable, errors.New("503"), false, true},
+		{"500 internal server error, idempotent", http.StatusInternalServerError, errors.New("500"), true, true},
+		{"500 internal server error, not idempotent", http.StatusInternalServerError, errors.New("500"), false, false},
+		{"502 bad gateway, idempotent", http.StatusBadGateway, errors.New("502"), true, true},
+		{"504 gateway timeout, not idempotent", http.StatusGatewayTimeout, errors.New("504"), false, false},
+		{"408 request timeout, idempotent", http.StatusRequestTimeout, errors.New("408"), true, true},
+		{"400 bad request", http.StatusBadRequest, errors.New("400"), true, false},
+		{"401 unauthorized", http.StatusUnauthorized, errors.New("401"), true, false},
+		{"404 not found", http.StatusNotFound, errors.New("404"), true, false},
+		{"connection refused, not idempotent", 0, dialErr, false, true},
+		{"dns error, not idempotent", 0, dnsErr, false, true},
+		{"connection reset, idempotent", 0, readErr, true, true},
+		{"connection reset, not idempotent", 0, readErr, false, false},
+		{"client timeout, idempotent", 0, fmt.Errorf("request failed: %w", context.DeadlineExceeded), true, true},
+		{"cancelled", 0, fmt.Errorf("request failed: %w", context.Canceled), true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var resp *http.Response
+			if tt.status != 0 {
+				resp = &http.Response{StatusCode: tt.status, Header: http.Header{}}
+			}
+
+			if got := isRetryable(resp, tt.err, tt.idempotent); got != tt.want {
+				t.Errorf("isRetryable() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	tests := []struct {
+		name       string
+		retryAfter string
+		attempt    int
+		min        time.Duration
+		max        time.Duration
+	}{
+		{"retry-after seconds", "7", 0, 7 * time.Second, 7 * time.Second},
+		{"retry-after zero", "0", 3, 0, 0},
+		{"retry-after date in the past", "Mon, 02 Jan 2006 15:04:05 GMT", 0, 0, 0},
+		{"invalid retry-after falls back to backoff", "soon", 0, retryBaseDelay / 2, retryBaseDelay},
+		{"negative retry-after falls back to backoff", "-5", 0, retryBaseDelay / 2, retryBaseDelay},
+		{"first attempt backoff", "", 0, retryBaseDelay / 2, retryBaseDelay},
+		{"third attempt backoff", "", 2, 2 * retryBaseDelay, 4 * retryBaseDelay},
+		{"backoff is capped", "", 10, retryMaxDelay / 2, retryMaxDelay},
+		{"backoff is capped without overflow", "", 100, retryMaxDelay / 2, retryMaxDelay},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			if tt.retryAfter != "" {
+				resp.Header.Set("Retry-After", tt.retryAfter)
+			}
+
+			for i := 0; i < 20; i++ {
+				if got := retryDelay(resp, tt.attempt); got < tt.min || got > tt.max {
+					t.Fatalf("retryDelay() = %v, want between %v and %v", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+
+	t.Run("retry-after date in the future", func(t *testing.T) {
+		resp := &http.Response{Header: http.Header{}}
+		resp.Header.Set("Retry-After", time.Now().Add(10*time.Second).UTC().Format(http.TimeFormat))
+
+		if got := retryDelay(resp, 0); got < 8*time.Second || got > 10*time.Second {
+			t.Errorf("retryDelay() = %v, want about 10s", got)
+		}
+	})
+}
+
+func TestExecuteWithRetry(t *testing.T) {
+	noWait := func() *http.Response {
+		resp := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+		resp.Header.Set("Retry-After", "0")
+		return resp
+	}
+
+	tests := []struct {
+		name         string
+		policy       RetryPolicy
+		failures     int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{"success without retries", RetryPolicy{MaxRetries: 3}, 0, 1, false},
+		{"success after retries", RetryPolicy{MaxRetries: 3}, 2, 3, false},
+		{"retries exhausted", RetryPolicy{MaxRetries: 2}, 5, 3, true},
+		{"retries disabled", RetryPolicy{MaxRetries: 0}, 5, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			_, err := executeWithRetry(context.Background(), tt.policy, true, func() (*http.Response, error) {
+				attempts++
+				if attempts <= tt.failures {
+					return noWait(), errors.New("503 Service Unavailable")
+				}
+				return &http.Response{StatusCode: http.StatusOK}, nil
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Errorf("executeWithRetry() error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	t.Run("retry-after beyond the retry timeout", func(t *testing.T) {
+		attempts := 0
+		_, err := executeWithRetry(context.Background(), RetryPolicy{MaxRetries: 3, Timeout: time.Second}, true, func() (*http.Response, error) {
+			attempts++
+			resp := &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}}
+			resp.Header.Set("Retry-After", "60")
+			return resp, errors.New("429 Too Many Requests")
+		})
+
+		if attempts != 1 || err == nil {
+			t.Errorf("attempts = %d, error = %v, want 1 attempt and an error", attempts, err)
+		}
+	})
+
+	t.Run("cancelled context", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		attempts := 0
+		_, err := executeWithRetry(ctx, RetryPolicy{MaxRetries: 3}, true, func() (*http.Response, error) {
+			attempts++
+			return nil, ctx.Err()
+		})
+
+		if attempts != 1 || !errors.Is(err, context.Canceled) {
+			t.Errorf("attempts = %d, error = %v, want 1 attempt and a cancelled error", attempts, err)
+		}
+	})
+}
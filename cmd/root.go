@@ -22,14 +22,24 @@ THE SOFTWARE.
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
+	"os/exec"
+	"os/signal"
 	"path/filepath"
+	"reflect"
 	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
@@ -45,9 +55,20 @@ const CredentialsYamlFile = "credentials.yaml"
 type LocalizationFile struct {
 	File       string
 	LocaleCode string
+	// FileType and JavaPropertiesEncoding override the global download params for this file, if set
+	FileType               string
+	JavaPropertiesEncoding string
 }
 type CredentialsYaml struct {
-	ApiToken string `yaml:"api_token"`
+	// ApiToken and ApiTokenCommand are used when no profile is selected
+	ApiToken        string                        `yaml:"api_token,omitempty"`
+	ApiTokenCommand string                        `yaml:"api_token_command,omitempty"`
+	Profiles        map[string]CredentialsProfile `yaml:"profiles,omitempty"`
+}
+
+type CredentialsProfile struct {
+	ApiToken        string `yaml:"api_token,omitempty"`
+	ApiTokenCommand string `yaml:"api_token_command,omitempty"`
 }
 
 type BaseLocalizelyYaml struct {
@@ -83,42 +104,111 @@ var javaPropertiesEncodingOpt = []string{
 	"latin_1",
 }
 
+var outputOpt = []string{
+	"text",
+	"json",
+	"ndjson",
+}
+
 var exportEmptyAsOpt = []string{
 	"empty",
 	"main",
 	"skip",
 }
 
+// configFile is the path of the config file set with the config flag
+var configFile string
+
+// commandCtx is the context of the running command, set before the command runs
+var commandCtx = context.Background()
+
 var rootCmd = &cobra.Command{
 	Use:     "localizely-cli",
 	Short:   "Localizely is a translation management platform that helps you translate texts in your app for targeting multilingual market.",
 	Version: Version,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		startCommandReport(cmd)
+		checkError(validateOutput(viper.GetString("output")))
+
+		ctx := cmd.Context()
+		if timeout := viper.GetDuration("timeout"); timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeoutCause(ctx, timeout, errTimeout)
+			cobra.OnFinalize(cancel)
+		}
+
+		commandCtx = ctx
+		cmd.SetContext(ctx)
+	},
 }
 
+// Execute runs the command with a context that is cancelled on SIGINT or SIGTERM, so in-flight requests are aborted and partial changes cleaned up.
+// A second signal terminates the process immediately.
 func Execute() {
-	err := rootCmd.Execute()
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		signal.Stop(signals)
+		cancel(errInterrupted)
+	}()
+
+	// Commands exit on their own errors, so the remaining errors are invalid usage, e.g. an unknown flag
+	err := rootCmd.ExecuteContext(ctx)
 	if err != nil {
-		os.Exit(1)
+		os.Exit(ExitCodeUsage)
 	}
 }
 
+// restoreDefaultSignals lets SIGINT and SIGTERM terminate the process immediately, for interactive commands that wait for input and have nothing to clean up.
+func restoreDefaultSignals() {
+	signal.Reset(os.Interrupt, syscall.SIGTERM)
+}
+
 func init() {
 	cobra.OnInitialize(initConfig)
+
+	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", fmt.Sprintf("Path to the config file (default is the %s file in the current directory or the nearest parent directory, up to the git root)", LocalizelyYamlFile))
+	rootCmd.PersistentFlags().String("profile", "", fmt.Sprintf("Name of the credentials profile from the %s file (default is the top-level api_token)", formatCredentialsYamlFilePath()))
+	rootCmd.PersistentFlags().String("api-url", "", "Base URL of the Localizely API, e.g. of an API gateway (default is https://api.localizely.com)")
+	rootCmd.PersistentFlags().String("ca-cert", "", "Path to a PEM file with CA certificates to trust in addition to the system ones, e.g. of a corporate proxy")
+	rootCmd.PersistentFlags().String("client-cert", "", "Path to a PEM client certificate for mutual TLS (used with client-key)")
+	rootCmd.PersistentFlags().String("client-key", "", "Path to the PEM private key of the client certificate")
+	rootCmd.PersistentFlags().Duration("request-timeout", 0, "Maximum time of a single HTTP request, including reading the response (0 for no limit)")
+	rootCmd.PersistentFlags().String("output", "text", "Output format, json and ndjson print machine-readable results\n"+formatOptions(outputOpt, 1, "unordered"))
+	rootCmd.PersistentFlags().Duration("timeout", 0, "Maximum total time of the command, after which it is cancelled (0 for no limit)")
+
+	// Persistent flags are shared by all commands, so they can be bound once
+	bindFlag("profile", rootCmd.PersistentFlags().Lookup("profile"))
+	bindFlag("api_url", rootCmd.PersistentFlags().Lookup("api-url"))
+	bindFlag("ca_cert", rootCmd.PersistentFlags().Lookup("ca-cert"))
+	bindFlag("client_cert", rootCmd.PersistentFlags().Lookup("client-cert"))
+	bindFlag("client_key", rootCmd.PersistentFlags().Lookup("client-key"))
+	bindFlag("request_timeout", rootCmd.PersistentFlags().Lookup("request-timeout"))
+	bindFlag("timeout", rootCmd.PersistentFlags().Lookup("timeout"))
+	bindFlag("output", rootCmd.PersistentFlags().Lookup("output"))
 }
 
 func initConfig() {
-	viper.AddConfigPath(".")
 	viper.SetConfigType("yaml")
-	viper.SetConfigName("localizely")
 
-	apiToken, err := getApiToken()
-	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			fmt.Fprintf(os.Stderr, "Failed to read api token from the '%s'\nError: %v\n", formatCredentialsYamlFilePath(), err)
-		}
+	if configFile == "" {
+		configFile = os.Getenv("LOCALIZELY_CONFIG")
+	}
+	explicitConfig := configFile != ""
+
+	if explicitConfig {
+		viper.SetConfigFile(configFile)
+	} else if path := findConfigFile(); path != "" {
+		viper.SetConfigFile(path)
+	} else {
+		viper.AddConfigPath(".")
+		viper.SetConfigName("localizely")
 	}
 
-	viper.SetDefault("api_token", apiToken)
 	viper.SetDefault("upload.files", []interface{}{})
 	viper.SetDefault("download.files", []interface{}{})
 
@@ -127,6 +217,116 @@ func initConfig() {
 
 	if err := viper.ReadInConfig(); err == nil {
 		fmt.Fprintf(os.Stderr, "Using config file: '%s'\n", viper.ConfigFileUsed())
+	} else if explicitConfig {
+		fmt.Fprintf(os.Stderr, "Failed to read config file '%s'\nError: %v\n", configFile, err)
+		os.Exit(ExitCodeConfig)
+	} else if !errors.As(err, &viper.ConfigFileNotFoundError{}) {
+		fmt.Fprintf(os.Stderr, "Failed to read config file '%s'\nError: %v\n", viper.ConfigFileUsed(), err)
+	}
+}
+
+// findConfigFile looks for the config file in the current directory and its parents, stopping at the git root.
+// It returns an empty string if the config file is not found.
+func findConfigFile() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	for {
+		for _, name := range []string{LocalizelyYamlFile, "localizely.yaml"} {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// configDir returns the directory of the config file relative to the current directory, or "." if no config file is used.
+func configDir() string {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return "."
+	}
+
+	dir := filepath.Dir(path)
+	if wd, err := os.Getwd(); err == nil {
+		if abs, err := filepath.Abs(dir); err == nil {
+			if rel, err := filepath.Rel(wd, abs); err == nil {
+				return rel
+			}
+		}
+	}
+
+	return dir
+}
+
+// resolveConfigPath resolves the relative path against the directory of the config file, so commands work the same from any subdirectory.
+func resolveConfigPath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+
+	return filepath.Join(configDir(), path)
+}
+
+// configPath returns the path of the key, resolved against the directory of the config file only if the value comes from the config file.
+// Paths from flags and environment variables stay relative to the current directory.
+func configPath(key string) string {
+	path := viper.GetString(key)
+	if !isConfigFileValue(key) {
+		return path
+	}
+
+	return resolveConfigPath(path)
+}
+
+// isConfigFileValue reports whether the value of the key comes from the config file, following the precedence of viper.
+func isConfigFileValue(key string) bool {
+	if flag, ok := boundFlags[key]; ok && flag.Changed {
+		return false
+	}
+
+	if _, ok := os.LookupEnv("LOCALIZELY_" + strings.ToUpper(key)); ok {
+		return false
+	}
+
+	return viper.InConfig(key)
+}
+
+// boundFlags are the flags bound to the config keys, so the source of a value can be told apart
+var boundFlags = map[string]*pflag.Flag{}
+
+func bindFlag(key string, flag *pflag.Flag) {
+	viper.BindPFlag(key, flag)
+	boundFlags[key] = flag
+}
+
+// ConfigFlag binds the config key to the flag with the given name.
+type ConfigFlag struct {
+	Key  string
+	Flag string
+}
+
+func bindConfigFlags(cmd *cobra.Command, flags []ConfigFlag) {
+	// Bind flags only if the command is executed (fixes issue with global viper and the same flag names in multiple cobra commands)
+	// More info: https://github.com/spf13/viper/issues/233#issuecomment-386791444
+	for _, v := range flags {
+		// Not every command has all the flags (e.g. watch has no concurrency flag)
+		if flag := cmd.Flags().Lookup(v.Flag); flag != nil {
+			bindFlag(v.Key, flag)
+		}
 	}
 }
 
@@ -150,42 +350,259 @@ func formatOptions(options []string, columns int, mode string) string {
 	return formatted
 }
 
+func formatPlanValue(value string) string {
+	if value == "" {
+		return "-"
+	}
+
+	return value
+}
+
 func formatCredentialsYamlFilePath() string {
 	home, _ := os.UserHomeDir()
 	return filepath.Join(home, LocalizelyDir, CredentialsYamlFile)
 }
 
-func getApiToken() (string, error) {
-	var err error
+// readApiToken returns the api token and its source, in order of precedence: the api_token key (flag, environment variable or config file),
+// the api token file, the LOCALIZELY_API_TOKEN_COMMAND environment variable, and the credentials file. The source is empty if the token comes from the api_token key.
+// The api token file and command are never read from the config file, so running the CLI in a cloned project cannot read files or run commands chosen by its author.
+func readApiToken() (string, string, error) {
+	if token := viper.GetString("api_token"); token != "" {
+		return token, "", nil
+	}
+
+	if path := apiTokenFile(); path != "" {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", newAuthError(fmt.Sprintf("Failed to read api token from the '%s' file\nError: %v\n", path, err))
+		}
+		return strings.TrimSpace(string(b)), "file " + path, nil
+	}
+
+	if command := os.Getenv("LOCALIZELY_API_TOKEN_COMMAND"); command != "" {
+		token, err := runApiTokenCommand(command)
+		return token, "command '" + command + "' from env LOCALIZELY_API_TOKEN_COMMAND", err
+	}
+
+	// The profile can be set in the config file, so it is read after the config file
+	profile := viper.GetString("profile")
+	credentialsYaml, err := readCredentialsYaml()
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", newAuthError(fmt.Sprintf("Failed to read api token from the '%s'\nError: %v\n", formatCredentialsYamlFilePath(), err))
+	}
+
+	// An undefined profile has no api token, which is reported when the api token is validated
+	token, command, source := credentialsYaml.ApiToken, credentialsYaml.ApiTokenCommand, formatCredentialsYamlFilePath()
+	if profile != "" {
+		token, command = credentialsYaml.Profiles[profile].ApiToken, credentialsYaml.Profiles[profile].ApiTokenCommand
+		source = fmt.Sprintf("%s (profile %s)", source, profile)
+	}
+
+	if token == "" && command != "" {
+		token, err := runApiTokenCommand(command)
+		return token, fmt.Sprintf("command '%s' from %s", command, source), err
+	}
+
+	return token, source, nil
+}
+
+// apiTokenFile returns the path of the api token file set with the api-token-file flag or the LOCALIZELY_API_TOKEN_FILE environment variable.
+func apiTokenFile() string {
+	if flag, ok := boundFlags["api_token_file"]; ok && flag.Changed {
+		return flag.Value.String()
+	}
+
+	return os.Getenv("LOCALIZELY_API_TOKEN_FILE")
+}
+
+// apiTokenCommandOutputs caches the tokens returned by the commands, so a command runs once even if several projects use it.
+var apiTokenCommandOutputs = map[string]string{}
+var apiTokenCommandMutex sync.Mutex
+
+// runApiTokenCommand runs the credential helper command in the shell and returns its output as the api token.
+// The standard input and error are passed through, so the command can ask for a passphrase.
+func runApiTokenCommand(command string) (string, error) {
+	apiTokenCommandMutex.Lock()
+	defer apiTokenCommandMutex.Unlock()
+
+	if token, ok := apiTokenCommandOutputs[command]; ok {
+		return token, nil
+	}
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", command)
+	} else {
+		cmd = exec.Command("sh", "-c", command)
+	}
+	cmd.Stdin = os.Stdin
+	cmd.Stderr = os.Stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", newAuthError(fmt.Sprintf("Failed to get api token from the command '%s'\nError: %v\n", command, err))
+	}
+
+	token := strings.TrimSpace(string(out))
+	if token == "" {
+		return "", newAuthError(fmt.Sprintf("The command '%s' returned an empty api token\n", command))
+	}
+
+	apiTokenCommandOutputs[command] = token
+	return token, nil
+}
+
+func readCredentialsYaml() (CredentialsYaml, error) {
+	var credentialsYaml CredentialsYaml
 
 	home, err := os.UserHomeDir()
 	if err != nil {
-		return "", err
+		return credentialsYaml, err
 	}
 
 	path := filepath.Join(home, LocalizelyDir, CredentialsYamlFile)
 
 	b, err := os.ReadFile(path)
 	if err != nil {
-		return "", err
+		return credentialsYaml, err
 	}
 
-	var credentialsYaml CredentialsYaml
 	err = yaml.Unmarshal(b, &credentialsYaml)
 	if err != nil {
-		return "", err
+		return credentialsYaml, err
 	}
 
-	return credentialsYaml.ApiToken, nil
+	return credentialsYaml, nil
 }
 
-func convertFilesConfigToLocalizationFiles(files []interface{}, localizationFiles *[]LocalizationFile) {
-	for _, v := range files {
-		*localizationFiles = append(*localizationFiles, LocalizationFile{
-			File:       (v.(map[string]interface{})["file"]).(string),
-			LocaleCode: (v.(map[string]interface{})["locale_code"]).(string),
-		})
+// readLocalizationFiles reads the list of localization files from the given key, which is set either from the config file or from the files flag.
+// The projectLocales function is called only if some entry uses 'locales: all'.
+func readLocalizationFiles(key string, projectLocales func() ([]string, error)) ([]LocalizationFile, error) {
+	files := viper.Get(key)
+
+	localizationFiles := []LocalizationFile{}
+	if reflect.TypeOf(files).String() == "[]interface {}" {
+		err := convertFilesConfigToLocalizationFiles(files.([]interface{}), &localizationFiles, projectLocales)
+		if err != nil {
+			return nil, err
+		}
+
+		// Only paths from the config file are relative to its directory, paths from the files flag are relative to the current directory
+		for i := range localizationFiles {
+			localizationFiles[i].File = resolveConfigPath(localizationFiles[i].File)
+		}
+	} else if reflect.TypeOf(files).String() == "map[string]interface {}" {
+		convertFilesFlagToLocalizationFiles(files.(map[string]interface{}), &localizationFiles)
+	}
+
+	return localizationFiles, nil
+}
+
+// convertFilesConfigToLocalizationFiles converts the file entries from the config file to localization files.
+// Entries with a 'locales' list are expanded into one localization file per locale, replacing the locale placeholders in the file path.
+func convertFilesConfigToLocalizationFiles(files []interface{}, localizationFiles *[]LocalizationFile, projectLocales func() ([]string, error)) error {
+	for i, v := range files {
+		entry, ok := v.(map[string]interface{})
+		if !ok {
+			return newConfigError(fmt.Sprintf("The localization file entry #%d has invalid value.\n\nEach entry must have the 'file' and 'locale_code' (or 'locales') keys.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", i+1))
+		}
+
+		file, _ := entry["file"].(string)
+		if file == "" {
+			return newConfigError(fmt.Sprintf("The localization file entry #%d is missing the 'file' key.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", i+1))
+		}
+
+		localeCodes, err := readEntryLocaleCodes(entry, i, projectLocales)
+		if err != nil {
+			return err
+		}
+
+		fileType, err := readEntryString(entry, "file_type", i)
+		if err != nil {
+			return err
+		}
+
+		javaPropertiesEncoding, err := readEntryString(entry, "java_properties_encoding", i)
+		if err != nil {
+			return err
+		}
+
+		for _, localeCode := range localeCodes {
+			*localizationFiles = append(*localizationFiles, LocalizationFile{
+				File:                   expandLocalePlaceholders(file, localeCode),
+				LocaleCode:             localeCode,
+				FileType:               fileType,
+				JavaPropertiesEncoding: javaPropertiesEncoding,
+			})
+		}
+	}
+
+	return nil
+}
+
+// readEntryString returns the value of the optional string key of the file entry, or an empty string if the key is not set.
+func readEntryString(entry map[string]interface{}, key string, index int) (string, error) {
+	value, ok := entry[key]
+	if !ok || value == nil {
+		return "", nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return "", newConfigError(fmt.Sprintf("The localization file entry #%d has invalid '%s' value.\n\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", index+1, key))
+	}
+
+	return s, nil
+}
+
+func readEntryLocaleCodes(entry map[string]interface{}, index int, projectLocales func() ([]string, error)) ([]string, error) {
+	localeCode, hasLocaleCode := entry["locale_code"]
+	locales, hasLocales := entry["locales"]
+
+	if hasLocaleCode && hasLocales {
+		return nil, newConfigError(fmt.Sprintf("The localization file entry #%d has both the 'locale_code' and 'locales' keys.\n\nPlease use 'locale_code' for a single file, or 'locales' for a file path pattern.\n\n", index+1))
+	}
+
+	if hasLocaleCode {
+		if s, ok := localeCode.(string); ok && s != "" {
+			return []string{s}, nil
+		}
+		return nil, newConfigError(fmt.Sprintf("The localization file entry #%d has invalid 'locale_code' value.\n\nExamples: en, de-DE, zh-Hans-CN\n\n", index+1))
+	}
+
+	if !hasLocales {
+		return nil, newConfigError(fmt.Sprintf("The localization file entry #%d is missing the 'locale_code' key.\n\nPlease set 'locale_code' for a single file, or 'locales' for a file path pattern.\n\n", index+1))
+	}
+
+	file, _ := entry["file"].(string)
+	if !hasLocalePlaceholders(file) {
+		return nil, newConfigError(fmt.Sprintf("The file path '%s' of the localization file entry #%d has no locale placeholders.\n\nAvailable placeholders: %s\n\n", file, index+1, strings.Join(localePlaceholders, ", ")))
+	}
+
+	switch v := locales.(type) {
+	case string:
+		if v == "all" {
+			if projectLocales == nil {
+				return nil, newConfigError(fmt.Sprintf("The 'locales: all' of the localization file entry #%d is not supported for this command.\n\n", index+1))
+			}
+			return projectLocales()
+		}
+	case []interface{}:
+		var localeCodes []string
+		for _, lc := range v {
+			s, ok := lc.(string)
+			if !ok || s == "" {
+				return nil, newConfigError(fmt.Sprintf("The localization file entry #%d has invalid 'locales' value.\n\nIt must be a list of locale codes, or 'all' for all project languages.\n\n", index+1))
+			}
+			localeCodes = append(localeCodes, s)
+		}
+		return localeCodes, nil
 	}
+
+	return nil, newConfigError(fmt.Sprintf("The localization file entry #%d has invalid 'locales' value.\n\nIt must be a list of locale codes, or 'all' for all project languages.\n\n", index+1))
 }
 
 func convertFilesFlagToLocalizationFiles(files map[string]interface{}, localizationFiles *[]LocalizationFile) {
@@ -218,8 +635,19 @@ func convertFilesFlagToLocalizationFiles(files map[string]interface{}, localizat
 		}
 	}
 
-	for _, v := range params {
-		if len(v) == 2 {
+	// The ids are sorted, so the files are in the order of the flag and the results are deterministic
+	ids := make([]string, 0, len(params))
+	for k := range params {
+		ids = append(ids, k)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := strconv.Atoi(ids[i])
+		b, _ := strconv.Atoi(ids[j])
+		return a < b
+	})
+
+	for _, id := range ids {
+		if v := params[id]; len(v) == 2 {
 			*localizationFiles = append(*localizationFiles, LocalizationFile{
 				File:       v["file"],
 				LocaleCode: v["locale_code"],
@@ -230,8 +658,13 @@ func convertFilesFlagToLocalizationFiles(files map[string]interface{}, localizat
 
 func validateApiToken(apiToken string) error {
 	if apiToken == "" {
-		msg := fmt.Sprintf("The API token was not provided.\n\nPlease set it using one of the available options:\n- %s file\n- LOCALIZELY_API_TOKEN environment variable\n- api-token flag\n\nTo create a new API token, please visit https://app.localizely.com/account.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatCredentialsYamlFilePath())
-		return errors.New(msg)
+		if profile := viper.GetString("profile"); profile != "" {
+			msg := fmt.Sprintf("The API token of the '%s' profile was not found.\n\nPlease save it using the \"localizely-cli login --profile %s\" command, or set it using one of the available options:\n- %s file\n- LOCALIZELY_API_TOKEN environment variable\n- api-token flag\n- api-token-file flag or LOCALIZELY_API_TOKEN_FILE environment variable\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", profile, profile, formatCredentialsYamlFilePath())
+			return newAuthError(msg)
+		}
+
+		msg := fmt.Sprintf("The API token was not provided.\n\nPlease set it using one of the available options:\n- %s file (optionally as a named profile, selected with the profile flag)\n- LOCALIZELY_API_TOKEN environment variable\n- api-token flag\n- api-token-file flag or LOCALIZELY_API_TOKEN_FILE environment variable\n- api_token_command key (e.g. \"pass show localizely/token\") in the %s file, or LOCALIZELY_API_TOKEN_COMMAND environment variable\n\nTo create a new API token, please visit https://app.localizely.com/account.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatCredentialsYamlFilePath(), formatCredentialsYamlFilePath())
+		return newAuthError(msg)
 	}
 
 	return nil
@@ -240,7 +673,7 @@ func validateApiToken(apiToken string) error {
 func validateProjectId(projectId string) error {
 	if projectId == "" {
 		msg := fmt.Sprintf("The project ID was not provided.\n\nPlease set it using one of the available options:\n- %s file (Learn more here https://localizely.com/configuration-file/)\n- LOCALIZELY_PROJECT_ID environment variable\n- project-id flag\n\nTo find your project ID, please visit https://app.localizely.com/projects\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", LocalizelyYamlFile)
-		return errors.New(msg)
+		return newConfigError(msg)
 	}
 
 	return nil
@@ -249,7 +682,7 @@ func validateProjectId(projectId string) error {
 func validateFileType(fileType string) error {
 	if fileType == "" {
 		msg := fmt.Sprintf("The file type was not provided.\n\nPlease set it using one of the available options:\n- %s file (Learn more here https://localizely.com/configuration-file/)\n- LOCALIZELY_FILE_TYPE environment variable\n- file-type flag\n\nAvailable file types:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", LocalizelyYamlFile, formatOptions(fileTypesOpt, 2, "unordered"))
-		return errors.New(msg)
+		return newConfigError(msg)
 	}
 
 	for _, ft := range fileTypesOpt {
@@ -259,13 +692,60 @@ func validateFileType(fileType string) error {
 	}
 
 	msg := fmt.Sprintf("The file type has invalid value.\n\nAvailable file types:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(fileTypesOpt, 2, "unordered"))
-	return errors.New(msg)
+	return newConfigError(msg)
 }
 
 func validateFiles(files []LocalizationFile, command string) error {
 	if len(files) == 0 {
 		msg := fmt.Sprintf("The list of localization files for %s was not provided.\n\nPlease set it using one of the available options:\n- %s file (Learn more here https://localizely.com/configuration-file/)\n- files flag\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", command, LocalizelyYamlFile)
-		return errors.New(msg)
+		return newConfigError(msg)
+	}
+
+	return nil
+}
+
+func validateFilePattern(filePattern string) error {
+	if filePattern == "" {
+		msg := fmt.Sprintf("The file pattern was not provided.\n\nIt is required when downloading all languages. Please set it using one of the available options:\n- %s file (download.file_pattern)\n- file-pattern flag\n\nAvailable placeholders: %s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", LocalizelyYamlFile, strings.Join(localePlaceholders, ", "))
+		return newConfigError(msg)
+	}
+
+	if !hasLocalePlaceholders(filePattern) {
+		msg := fmt.Sprintf("The file pattern '%s' has no locale placeholders.\n\nAvailable placeholders: %s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", filePattern, strings.Join(localePlaceholders, ", "))
+		return newConfigError(msg)
+	}
+
+	return nil
+}
+
+func validateFilesExist(files []LocalizationFile) error {
+	for _, v := range files {
+		if _, err := os.Stat(filepath.Clean(v.File)); err != nil {
+			return newConfigError(fmt.Sprintf("Failed to open file '%s'\nError: %v\n", filepath.Clean(v.File), err))
+		}
+	}
+
+	return nil
+}
+
+func validateConcurrency(concurrency int) error {
+	if concurrency < 1 {
+		msg := "The concurrency has invalid value.\n\nIt must be a positive number.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n"
+		return newConfigError(msg)
+	}
+
+	return nil
+}
+
+func validateRetryPolicy(retryPolicy RetryPolicy) error {
+	if retryPolicy.MaxRetries < 0 {
+		msg := "The max-retries has invalid value.\n\nIt must be zero or a positive number.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n"
+		return newConfigError(msg)
+	}
+
+	if retryPolicy.Timeout < 0 {
+		msg := "The retry-timeout has invalid value.\n\nIt must be zero or a positive duration (e.g. 30s, 2m).\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n"
+		return newConfigError(msg)
 	}
 
 	return nil
@@ -283,7 +763,7 @@ func validateExportEmptyAs(exportEmptyAs string) error {
 	}
 
 	msg := fmt.Sprintf("The export-empty-as has invalid value.\n\nAvailable options:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(exportEmptyAsOpt, 1, "unordered"))
-	return errors.New(msg)
+	return newConfigError(msg)
 }
 
 func validateJavaPropertiesEncoding(javaPropertiesEncoding string) error {
@@ -298,7 +778,18 @@ func validateJavaPropertiesEncoding(javaPropertiesEncoding string) error {
 	}
 
 	msg := fmt.Sprintf("The java properties encoding has invalid value.\n\nAvailable options:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(javaPropertiesEncodingOpt, 1, "unordered"))
-	return errors.New(msg)
+	return newConfigError(msg)
+}
+
+func validateOutput(output string) error {
+	for _, opt := range outputOpt {
+		if opt == output {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("The output has invalid value.\n\nAvailable output options:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(outputOpt, 1, "unordered"))
+	return newConfigError(msg)
 }
 
 func validateMode(mode string) error {
@@ -313,12 +804,19 @@ func validateMode(mode string) error {
 	}
 
 	msg := fmt.Sprintf("The mode has invalid value.\n\nAvailable mode options:\n%s\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", formatOptions(modeOpt, 1, "unordered"))
-	return errors.New(msg)
+	return newConfigError(msg)
 }
 
 func checkError(err error) {
 	if err != nil {
 		fmt.Fprint(os.Stderr, err)
-		os.Exit(1)
+		reportFailure(err)
+
+		// Requests fail with the error of the cancelled context, so the cause is printed too
+		if cause := context.Cause(commandCtx); cause != nil {
+			fmt.Fprintf(os.Stderr, "\n%v\n", cause)
+		}
+
+		os.Exit(exitCode(err))
 	}
 }
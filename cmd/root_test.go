@@ -0,0 +1,58 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestConvertFilesFlagToLocalizationFiles(t *testing.T) {
+	files := map[string]interface{}{
+		"file[0]":         "lang/en.json",
+		"locale_code[0]":  "en",
+		"file[2]":         "lang/fr.json",
+		"locale_code[2]":  "fr",
+		"file[10]":        "lang/it.json",
+		"locale_code[10]": "it",
+		"file[1]":         "lang/de.json",
+		"locale_code[1]":  "de",
+		// Entries without a locale code are ignored
+		"file[3]": "lang/es.json",
+	}
+	want := []LocalizationFile{
+		{File: "lang/en.json", LocaleCode: "en"},
+		{File: "lang/de.json", LocaleCode: "de"},
+		{File: "lang/fr.json", LocaleCode: "fr"},
+		{File: "lang/it.json", LocaleCode: "it"},
+	}
+
+	// The map order is random, so the conversion is repeated to catch an unstable order
+	for i := 0; i < 20; i++ {
+		var got []LocalizationFile
+		convertFilesFlagToLocalizationFiles(files, &got)
+
+		if !slices.Equal(got, want) {
+			t.Fatalf("convertFilesFlagToLocalizationFiles() = %v, want %v", got, want)
+		}
+	}
+}
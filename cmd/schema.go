@@ -0,0 +1,509 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	configTypeString   = "string"
+	configTypeBoolean  = "boolean"
+	configTypeInteger  = "integer"
+	configTypeDuration = "duration"
+	configTypeObject   = "object"
+	configTypeList     = "list"
+	// configTypeLocales is a list of locale codes, or 'all' for all project languages
+	configTypeLocales = "locales"
+)
+
+// ConfigKey describes a key of the localizely.yml file. The schema is the single source of truth for validating the config file.
+type ConfigKey struct {
+	Name        string
+	Type        string
+	Description string
+	Required    bool
+	Enum        []string
+	// Keys are the keys of an object
+	Keys []*ConfigKey
+	// Items describes the items of a list
+	Items *ConfigKey
+	// OneOf are the keys of an object of which exactly one must be set
+	OneOf []string
+	// Check validates rules that involve more than one key
+	Check func(node *yaml.Node, path string) []ConfigProblem
+	// Unsupported keys are known, so they get a helpful message from the Check, but are never valid
+	Unsupported bool
+}
+
+// ConfigProblem is a problem found in the config file, with the position of the offending node.
+type ConfigProblem struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func uploadFileSchema() *ConfigKey {
+	return &ConfigKey{
+		Type:  configTypeObject,
+		OneOf: []string{"locale_code", "locales"},
+		Check: checkFilePattern,
+		Keys: []*ConfigKey{
+			{Name: "file", Type: configTypeString, Required: true, Description: "Path to the translation file, or a path pattern with locale placeholders: " + strings.Join(localePlaceholders, ", ")},
+			{Name: "locale_code", Type: configTypeString, Description: "Locale code for the file. Examples: en, de-DE, zh-Hans-CN"},
+			{Name: "locales", Type: configTypeLocales, Description: "Required for path patterns instead of locale_code. List of locale codes to expand the pattern for, or 'all' for all project languages"},
+		},
+	}
+}
+
+func downloadFileSchema() *ConfigKey {
+	schema := uploadFileSchema()
+	schema.Keys = append(schema.Keys,
+		&ConfigKey{Name: "file_type", Type: configTypeString, Enum: fileTypesOpt, Description: "File type of this file, if it differs from the global file_type"},
+		&ConfigKey{Name: "java_properties_encoding", Type: configTypeString, Enum: javaPropertiesEncodingOpt, Description: "Character encoding of this file, if it differs from the global java_properties_encoding param"},
+	)
+	return schema
+}
+
+func uploadSchema() *ConfigKey {
+	return &ConfigKey{
+		Name:        "upload",
+		Type:        configTypeObject,
+		Description: "Settings for pushing files to Localizely",
+		Keys: []*ConfigKey{
+			{Name: "files", Type: configTypeList, Items: uploadFileSchema(), Description: "List of files for upload to Localizely. Usually, it is just one file used for the main locale"},
+			{Name: "params", Type: configTypeObject, Keys: []*ConfigKey{
+				{Name: "overwrite", Type: configTypeBoolean, Description: "If the translation in a given language should be overwritten with modified translation from uploading file. Default: false"},
+				{Name: "reviewed", Type: configTypeBoolean, Description: "If uploading translations, that are added, should be marked as Reviewed. For uploading translations that are only modified it will have effect only if overwrite is set to true. Default: false"},
+				{Name: "tag_added", Type: configTypeList, Items: &ConfigKey{Type: configTypeString}, Description: "List of tags to add to new translations from uploading file"},
+				{Name: "tag_removed", Type: configTypeList, Items: &ConfigKey{Type: configTypeString}, Description: "List of tags to add to removed translations from uploading file"},
+				{Name: "tag_updated", Type: configTypeList, Items: &ConfigKey{Type: configTypeString}, Description: "List of tags to add to updated translations from uploading file"},
+				{Name: "concurrency", Type: configTypeInteger, Description: "Number of files to upload in parallel. Default: 1"},
+				{Name: "skip_unchanged", Type: configTypeBoolean, Description: "Skip files whose content has not changed since the last push to the same project and branch. Default: false"},
+			}},
+		},
+	}
+}
+
+func downloadSchema() *ConfigKey {
+	return &ConfigKey{
+		Name:        "download",
+		Type:        configTypeObject,
+		Description: "Settings for pulling files from Localizely",
+		Keys: []*ConfigKey{
+			{Name: "files", Type: configTypeList, Items: downloadFileSchema(), Description: "List of files for download from Localizely"},
+			{Name: "all_languages", Type: configTypeBoolean, Description: "Download all languages of the project. Languages not listed in the files are saved to the path derived from the file_pattern. Default: false"},
+			{Name: "file_pattern", Type: configTypeString, Description: "Required if all_languages is true. Path pattern for languages not listed in the files"},
+			{Name: "params", Type: configTypeObject, Keys: []*ConfigKey{
+				{Name: "export_empty_as", Type: configTypeString, Enum: exportEmptyAsOpt, Description: "How you would like empty translations to be exported. Allowed values are 'empty' to keep empty, 'main' to replace with the main language value, or 'skip' to omit. Default: empty"},
+				{Name: "exclude_tags", Type: configTypeList, Items: &ConfigKey{Type: configTypeString}, Description: "List of tags to be excluded from the download. If not set, all string keys will be considered for download"},
+				{Name: "include_tags", Type: configTypeList, Items: &ConfigKey{Type: configTypeString}, Description: "List of tags to be downloaded. If not set, all string keys will be considered for download"},
+				{Name: "java_properties_encoding", Type: configTypeString, Enum: javaPropertiesEncodingOpt, Description: "(Only for Java .properties files download) Character encoding. Default: latin_1"},
+				{Name: "concurrency", Type: configTypeInteger, Description: "Number of files to download in parallel. Default: 1"},
+			}},
+		},
+	}
+}
+
+// projectSettingsSchema returns the keys that can be set both at the top level and for each project.
+func projectSettingsSchema() []*ConfigKey {
+	return []*ConfigKey{
+		{Name: "project_id", Type: configTypeString, Description: "Your project ID from: https://app.localizely.com/projects"},
+		{Name: "file_type", Type: configTypeString, Enum: fileTypesOpt, Description: "File type of the localization files"},
+		{Name: "branch", Type: configTypeString, Description: "Your branch in Localizely project to sync files with"},
+		{Name: "max_retries", Type: configTypeInteger, Description: "Maximum number of retries for transient API failures (e.g. 429, 503, connection errors). Default: 3"},
+		{Name: "retry_timeout", Type: configTypeDuration, Description: "Maximum total time to spend on retries of a single request. Set to 0 for no limit. Default: 1m"},
+		{Name: "api_url", Type: configTypeString, Check: checkApiUrl, Description: "Base URL of the Localizely API, e.g. of an API gateway. Default is https://api.localizely.com"},
+		{Name: "ca_cert", Type: configTypeString, Description: "Path to a PEM file with CA certificates to trust in addition to the system ones, e.g. of a corporate proxy"},
+		{Name: "client_cert", Type: configTypeString, Description: "Path to a PEM client certificate for mutual TLS. Requires client_key"},
+		{Name: "client_key", Type: configTypeString, Description: "Path to the PEM private key of the client certificate"},
+		{Name: "request_timeout", Type: configTypeDuration, Description: "Maximum time of a single HTTP request, including reading the response. Set to 0 for no limit. Default: 0"},
+		uploadSchema(),
+		downloadSchema(),
+	}
+}
+
+// configSchema describes the localizely.yml file.
+var configSchema = &ConfigKey{
+	Type: configTypeObject,
+	Keys: append([]*ConfigKey{
+		{Name: "config_version", Type: configTypeString, Required: true, Enum: []string{"1.0"}, Description: "Version of the config file format. Only 1.0 available"},
+		{Name: "profile", Type: configTypeString, Description: "Name of the profile in the ~/.localizely/credentials.yaml file whose api token is used for this project"},
+		{Name: "api_token", Type: configTypeString, Description: "API token from https://app.localizely.com/account. Prefer the ~/.localizely/credentials.yaml file, so the token is not committed with the project"},
+		{Name: "api_token_command", Type: configTypeString, Unsupported: true, Check: checkApiTokenCommand, Description: "Not supported in this file, so a cloned project cannot run commands. Set it in the ~/.localizely/credentials.yaml file or the LOCALIZELY_API_TOKEN_COMMAND environment variable instead"},
+		{Name: "timeout", Type: configTypeDuration, Description: "Maximum total time of a command, after which it is cancelled. Set to 0 for no limit. Default: 0"},
+		{Name: "projects", Type: configTypeList, Check: checkProjectNames, Description: "List of projects for monorepos. Each project has a unique name and settings that override the top-level settings", Items: &ConfigKey{
+			Type: configTypeObject,
+			Keys: append([]*ConfigKey{
+				{Name: "name", Type: configTypeString, Required: true, Description: "Unique name of the project, used with the project flag"},
+			}, projectSettingsSchema()...),
+		}},
+	}, projectSettingsSchema()...),
+}
+
+// validateConfigFile checks the config file against the config schema and returns every problem found.
+func validateConfigFile(path string) ([]ConfigProblem, error) {
+	root, err := readConfigFileNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	problems := validateConfigNode(configSchema, root, "")
+	sort.SliceStable(problems, func(i, j int) bool {
+		if problems[i].Line != problems[j].Line {
+			return problems[i].Line < problems[j].Line
+		}
+		return problems[i].Column < problems[j].Column
+	})
+
+	return problems, nil
+}
+
+// readConfigFileNode parses the config file into a YAML node tree, which keeps the line and column of every value.
+func readConfigFileNode(path string) (*yaml.Node, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, newConfigError(fmt.Sprintf("Failed to read the '%s' file\nError: %v\n", path, err))
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, newConfigError(fmt.Sprintf("Failed to parse the '%s' file\nError: %v\n", path, err))
+	}
+
+	if doc.Kind == yaml.DocumentNode && len(doc.Content) > 0 {
+		return doc.Content[0], nil
+	}
+
+	return &yaml.Node{Kind: yaml.MappingNode, Line: 1, Column: 1}, nil
+}
+
+// checkConfigFile validates the config file used by viper, if any, so invalid settings are reported instead of being ignored.
+func checkConfigFile() error {
+	path := viper.ConfigFileUsed()
+	if path == "" {
+		return nil
+	}
+
+	problems, err := validateConfigFile(path)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return newConfigError(fmt.Sprintf("The '%s' file is invalid.\n\n%s\nUse \"localizely-cli config validate\" to check the config file.\nFor more configuration details, see https://localizely.com/configuration-file/\n\n", path, formatConfigProblems(path, problems)))
+	}
+
+	return nil
+}
+
+func formatConfigProblems(path string, problems []ConfigProblem) string {
+	formatted := ""
+	for _, v := range problems {
+		formatted += fmt.Sprintf("%s:%d:%d: %s\n", path, v.Line, v.Column, v.Message)
+	}
+	return formatted
+}
+
+func validateConfigNode(key *ConfigKey, node *yaml.Node, path string) []ConfigProblem {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	// Empty values are treated as not set
+	if node.Kind == yaml.ScalarNode && node.Tag == "!!null" {
+		if key.Required {
+			return []ConfigProblem{newConfigProblem(node, "'%s' must not be empty", path)}
+		}
+		return nil
+	}
+
+	var problems []ConfigProblem
+
+	switch key.Type {
+	case configTypeObject:
+		if node.Kind != yaml.MappingNode {
+			return []ConfigProblem{newConfigProblem(node, "'%s' must be a map of keys", displayConfigPath(path))}
+		}
+		problems = append(problems, validateConfigObject(key, node, path)...)
+
+	case configTypeList:
+		if node.Kind != yaml.SequenceNode {
+			return []ConfigProblem{newConfigProblem(node, "'%s' must be a list", displayConfigPath(path))}
+		}
+		for i, item := range node.Content {
+			problems = append(problems, validateConfigNode(key.Items, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+	case configTypeLocales:
+		if node.Kind == yaml.ScalarNode {
+			if node.Value != "all" {
+				return []ConfigProblem{newConfigProblem(node, "'%s' must be a list of locale codes, or 'all' for all project languages", path)}
+			}
+			break
+		}
+		if node.Kind != yaml.SequenceNode {
+			return []ConfigProblem{newConfigProblem(node, "'%s' must be a list of locale codes, or 'all' for all project languages", path)}
+		}
+		for i, item := range node.Content {
+			problems = append(problems, validateConfigNode(&ConfigKey{Type: configTypeString}, item, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+
+	default:
+		if problem, ok := validateConfigScalar(key, node, path); !ok {
+			return []ConfigProblem{problem}
+		}
+	}
+
+	if key.Check != nil {
+		problems = append(problems, key.Check(node, path)...)
+	}
+
+	return problems
+}
+
+func validateConfigObject(key *ConfigKey, node *yaml.Node, path string) []ConfigProblem {
+	var problems []ConfigProblem
+
+	keys := map[string]*ConfigKey{}
+	var names []string
+	for _, v := range key.Keys {
+		keys[v.Name] = v
+		names = append(names, v.Name)
+	}
+
+	found := map[string]bool{}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i], node.Content[i+1]
+
+		child, ok := keys[name.Value]
+		if !ok {
+			msg := fmt.Sprintf("unknown key '%s'", name.Value)
+			if path != "" {
+				msg += fmt.Sprintf(" in '%s'", path)
+			}
+			if suggestion := suggestConfigKey(name.Value, names); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean '%s'?)", suggestion)
+			}
+			problems = append(problems, ConfigProblem{Line: name.Line, Column: name.Column, Message: msg})
+			continue
+		}
+		found[name.Value] = true
+
+		problems = append(problems, validateConfigNode(child, value, joinConfigPath(path, name.Value))...)
+	}
+
+	for _, v := range key.Keys {
+		if v.Required && !found[v.Name] {
+			problems = append(problems, newConfigProblem(node, "'%s' is missing the required key '%s'", displayConfigPath(path), v.Name))
+		}
+	}
+
+	if len(key.OneOf) > 0 {
+		var set []string
+		for _, v := range key.OneOf {
+			if findConfigValue(node, v) != nil {
+				set = append(set, v)
+			}
+		}
+		if len(set) != 1 {
+			problems = append(problems, newConfigProblem(node, "'%s' must have exactly one of the keys '%s'", displayConfigPath(path), strings.Join(key.OneOf, "', '")))
+		}
+	}
+
+	return problems
+}
+
+func validateConfigScalar(key *ConfigKey, node *yaml.Node, path string) (ConfigProblem, bool) {
+	if node.Kind != yaml.ScalarNode {
+		return newConfigProblem(node, "'%s' must be a %s", path, key.Type), false
+	}
+
+	switch key.Type {
+	case configTypeBoolean:
+		if node.Tag != "!!bool" {
+			return newConfigProblem(node, "'%s' must be true or false", path), false
+		}
+	case configTypeInteger:
+		if node.Tag != "!!int" {
+			return newConfigProblem(node, "'%s' must be an integer", path), false
+		}
+	case configTypeDuration:
+		if node.Tag != "!!int" {
+			if _, err := time.ParseDuration(node.Value); err != nil {
+				return newConfigProblem(node, "'%s' must be a duration (e.g. 30s, 1m, 1h30m)", path), false
+			}
+		}
+	}
+
+	if len(key.Enum) > 0 {
+		for _, v := range key.Enum {
+			if v == node.Value || isSameNumber(node, v) {
+				return ConfigProblem{}, true
+			}
+		}
+		return newConfigProblem(node, "'%s' has invalid value '%s', available values: %s", path, node.Value, strings.Join(key.Enum, ", ")), false
+	}
+
+	return ConfigProblem{}, true
+}
+
+// isSameNumber reports whether the node is an unquoted number equal to the value, e.g. config_version: 1 for the value 1.0.
+// YAML parses unquoted numbers as numbers, so they are compared as numbers, in the same way as in the JSON schema of the config file.
+func isSameNumber(node *yaml.Node, value string) bool {
+	if node.Tag != "!!int" && node.Tag != "!!float" {
+		return false
+	}
+
+	a, err := strconv.ParseFloat(node.Value, 64)
+	if err != nil {
+		return false
+	}
+
+	b, err := strconv.ParseFloat(value, 64)
+	return err == nil && a == b
+}
+
+// checkFilePattern checks that the file path of an entry with a locales list has locale placeholders.
+func checkFilePattern(node *yaml.Node, path string) []ConfigProblem {
+	file := findConfigValue(node, "file")
+	locales := findConfigValue(node, "locales")
+
+	if locales != nil && file != nil && !hasLocalePlaceholders(file.Value) {
+		return []ConfigProblem{newConfigProblem(file, "'%s.file' has no locale placeholders, available placeholders: %s", path, strings.Join(localePlaceholders, ", "))}
+	}
+
+	return nil
+}
+
+// checkApiUrl checks that the API URL is an absolute http or https URL.
+func checkApiUrl(node *yaml.Node, path string) []ConfigProblem {
+	if validateApiUrl(node.Value) != nil {
+		return []ConfigProblem{newConfigProblem(node, "'%s' must be an absolute http or https URL", path)}
+	}
+
+	return nil
+}
+
+// checkApiTokenCommand rejects the api token command, which is only read from the credentials file and the environment of the user.
+func checkApiTokenCommand(node *yaml.Node, path string) []ConfigProblem {
+	return []ConfigProblem{newConfigProblem(node, "'%s' is not supported in the %s file, so running the CLI in a cloned project cannot run commands; set it in the %s file or the LOCALIZELY_API_TOKEN_COMMAND environment variable", path, LocalizelyYamlFile, formatCredentialsYamlFilePath())}
+}
+
+func checkProjectNames(node *yaml.Node, path string) []ConfigProblem {
+	var problems []ConfigProblem
+
+	names := map[string]bool{}
+	for _, item := range node.Content {
+		name := findConfigValue(item, "name")
+		if name == nil || name.Value == "" {
+			continue
+		}
+		if names[name.Value] {
+			problems = append(problems, newConfigProblem(name, "the project name '%s' is used by more than one project", name.Value))
+		}
+		names[name.Value] = true
+	}
+
+	return problems
+}
+
+// findConfigValue returns the value node of the key in the map node, or nil if the key is not set.
+func findConfigValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			value := node.Content[i+1]
+			if value.Kind == yaml.ScalarNode && value.Tag == "!!null" {
+				return nil
+			}
+			return value
+		}
+	}
+
+	return nil
+}
+
+// suggestConfigKey returns the known key closest to the unknown key, if it is likely a typo.
+func suggestConfigKey(name string, known []string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(name, "-", "_"))
+
+	best, bestDistance := "", 3
+	for _, v := range known {
+		if d := editDistance(normalized, v); d < bestDistance {
+			best, bestDistance = v, d
+		}
+	}
+
+	return best
+}
+
+func editDistance(a string, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func newConfigProblem(node *yaml.Node, format string, args ...interface{}) ConfigProblem {
+	return ConfigProblem{Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)}
+}
+
+func joinConfigPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func displayConfigPath(path string) string {
+	if path == "" {
+		return "config file"
+	}
+	return path
+}
@@ -0,0 +1,187 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []ConfigProblem
+	}{
+		{
+			name: "valid",
+			config: `config_version: '1.0'
+project_id: 1a2b3c
+file_type: flutter_arb
+max_retries: 5
+retry_timeout: 2m
+upload:
+  files:
+    - file: lib/l10n/intl_en.arb
+      locale_code: en
+download:
+  files:
+    - file: lib/l10n/intl_{locale}.arb
+      locales: all
+  params:
+    export_empty_as: main
+`,
+		},
+		{
+			name:   "missing config version",
+			config: "project_id: 1a2b3c\n",
+			want:   []ConfigProblem{{1, 1, "'config file' is missing the required key 'config_version'"}},
+		},
+		{
+			name:   "unquoted config version",
+			config: "config_version: 1.0\n",
+		},
+		{
+			name:   "integer config version",
+			config: "config_version: 1\n",
+		},
+		{
+			name:   "quoted integer config version",
+			config: "config_version: '1'\n",
+			want:   []ConfigProblem{{1, 17, "'config_version' has invalid value '1', available values: 1.0"}},
+		},
+		{
+			name:   "unsupported config version",
+			config: "config_version: 2.0\n",
+			want:   []ConfigProblem{{1, 17, "'config_version' has invalid value '2.0', available values: 1.0"}},
+		},
+		{
+			name:   "unknown key with suggestion",
+			config: "config_version: '1.0'\nproject-id: 1a2b3c\n",
+			want:   []ConfigProblem{{2, 1, "unknown key 'project-id' (did you mean 'project_id'?)"}},
+		},
+		{
+			name:   "unknown nested key",
+			config: "config_version: '1.0'\nupload:\n  params:\n    overwrite: true\n    colour: red\n",
+			want:   []ConfigProblem{{5, 5, "unknown key 'colour' in 'upload.params'"}},
+		},
+		{
+			name:   "invalid enum value",
+			config: "config_version: '1.0'\nfile_type: yaml\n",
+			want:   []ConfigProblem{{2, 12, "'file_type' has invalid value 'yaml', available values: " + strings.Join(fileTypesOpt, ", ")}},
+		},
+		{
+			name:   "invalid scalar types",
+			config: "config_version: '1.0'\nmax_retries: three\nretry_timeout: soon\nupload:\n  params:\n    overwrite: yes please\n",
+			want: []ConfigProblem{
+				{2, 14, "'max_retries' must be an integer"},
+				{3, 16, "'retry_timeout' must be a duration (e.g. 30s, 1m, 1h30m)"},
+				{6, 16, "'upload.params.overwrite' must be true or false"},
+			},
+		},
+		{
+			name:   "list instead of map",
+			config: "config_version: '1.0'\nupload:\n  - file: en.json\n",
+			want:   []ConfigProblem{{3, 3, "'upload' must be a map of keys"}},
+		},
+		{
+			name:   "both locale_code and locales",
+			config: "config_version: '1.0'\nupload:\n  files:\n    - file: lang/{locale}.json\n      locale_code: en\n      locales: [en, de]\n",
+			want:   []ConfigProblem{{4, 7, "'upload.files[0]' must have exactly one of the keys 'locale_code', 'locales'"}},
+		},
+		{
+			name:   "locales without placeholders",
+			config: "config_version: '1.0'\ndownload:\n  files:\n    - file: lang/en.json\n      locales: [en, de]\n",
+			want:   []ConfigProblem{{4, 13, "'download.files[0].file' has no locale placeholders, available placeholders: " + strings.Join(localePlaceholders, ", ")}},
+		},
+		{
+			name:   "invalid locales",
+			config: "config_version: '1.0'\ndownload:\n  files:\n    - file: lang/{locale}.json\n      locales: some\n",
+			want:   []ConfigProblem{{5, 16, "'download.files[0].locales' must be a list of locale codes, or 'all' for all project languages"}},
+		},
+		{
+			name:   "invalid api url",
+			config: "config_version: '1.0'\napi_url: localhost:8080\n",
+			want:   []ConfigProblem{{2, 10, "'api_url' must be an absolute http or https URL"}},
+		},
+		{
+			name:   "duplicate and missing project names",
+			config: "config_version: '1.0'\nprojects:\n  - name: web\n    project_id: a\n  - name: web\n    project_id: b\n  - project_id: c\n",
+			want: []ConfigProblem{
+				{5, 11, "the project name 'web' is used by more than one project"},
+				{7, 5, "'projects[2]' is missing the required key 'name'"},
+			},
+		},
+		{
+			name:   "per-project settings",
+			config: "config_version: '1.0'\nprojects:\n  - name: web\n    project_id: a\n    api_url: https://gateway.example.com\n    request_timeout: 30s\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), LocalizelyYamlFile)
+			if err := os.WriteFile(path, []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			got, err := validateConfigFile(path)
+			if err != nil {
+				t.Fatalf("validateConfigFile() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("validateConfigFile() =\n%v\nwant\n%v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateConfigFileApiTokenCommand(t *testing.T) {
+	path := filepath.Join(t.TempDir(), LocalizelyYamlFile)
+	if err := os.WriteFile(path, []byte("config_version: '1.0'\napi_token_command: cat token.txt\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := validateConfigFile(path)
+	if err != nil {
+		t.Fatalf("validateConfigFile() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Line != 2 {
+		t.Errorf("validateConfigFile() = %v, want a problem on line 2", got)
+	}
+}
+
+func TestBuildJsonSchemaUnsupportedKey(t *testing.T) {
+	properties := buildJsonSchema(configSchema)["properties"].(map[string]interface{})
+
+	// The validation rejects the api token command in the config file, so the JSON schema must not accept any value
+	schema := properties["api_token_command"].(map[string]interface{})
+	if _, ok := schema["not"]; !ok {
+		t.Errorf("schema of api_token_command = %v, want a schema that accepts no value", schema)
+	}
+	if _, ok := schema["type"]; ok {
+		t.Errorf("schema of api_token_command = %v, want no type", schema)
+	}
+}
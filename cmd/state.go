@@ -0,0 +1,221 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const StateJsonFile = "state.json"
+
+const stateVersion = 1
+
+// SyncState records, per localization file, the last successful push and pull.
+// It is stored in the project (not in the home directory), next to the localizely.yml file.
+type SyncState struct {
+	Version int                       `json:"version"`
+	Files   map[string]*SyncStateFile `json:"files"`
+}
+
+type SyncStateFile struct {
+	LocaleCode string          `json:"locale_code"`
+	Push       *SyncStateEntry `json:"push,omitempty"`
+	Pull       *SyncStateEntry `json:"pull,omitempty"`
+}
+
+type SyncStateEntry struct {
+	Hash       string    `json:"hash"`
+	Timestamp  time.Time `json:"timestamp"`
+	ProjectId  string    `json:"project_id"`
+	Branch     string    `json:"branch,omitempty"`
+	CliVersion string    `json:"cli_version"`
+}
+
+func formatStateJsonFilePath() string {
+	return filepath.Join(configDir(), LocalizelyDir, StateJsonFile)
+}
+
+func readSyncState() (*SyncState, error) {
+	state := &SyncState{Version: stateVersion, Files: map[string]*SyncStateFile{}}
+
+	b, err := os.ReadFile(formatStateJsonFilePath())
+	if errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(b, state); err != nil {
+		return nil, err
+	}
+	if state.Files == nil {
+		state.Files = map[string]*SyncStateFile{}
+	}
+
+	return state, nil
+}
+
+func writeSyncState(state *SyncState) error {
+	b, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tx := newFileTransaction()
+	if err := tx.Stage(formatStateJsonFilePath(), append(b, '\n')); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// saveSyncState updates the state file, only warning on failure as the synced files are already in place.
+func saveSyncState(update func(state *SyncState)) {
+	state, err := readSyncState()
+	if err == nil {
+		update(state)
+		err = writeSyncState(state)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to update the '%s' file\nError: %v\n", formatStateJsonFilePath(), err)
+	}
+}
+
+// stateKey returns the path of the file relative to the config file directory, so the key does not depend on the current directory.
+func stateKey(file LocalizationFile) string {
+	path := filepath.Clean(file.File)
+
+	dir, dirErr := filepath.Abs(configDir())
+	abs, absErr := filepath.Abs(path)
+	if dirErr == nil && absErr == nil {
+		if rel, err := filepath.Rel(dir, abs); err == nil {
+			path = rel
+		}
+	}
+
+	return filepath.ToSlash(path)
+}
+
+func newSyncStateEntry(hash string, projectId string, branch string) *SyncStateEntry {
+	return &SyncStateEntry{
+		Hash:       hash,
+		Timestamp:  time.Now().UTC().Truncate(time.Second),
+		ProjectId:  projectId,
+		Branch:     branch,
+		CliVersion: Version,
+	}
+}
+
+func (s *SyncState) file(file LocalizationFile) *SyncStateFile {
+	key := stateKey(file)
+
+	v, ok := s.Files[key]
+	if !ok || v.LocaleCode != file.LocaleCode {
+		v = &SyncStateFile{LocaleCode: file.LocaleCode}
+		s.Files[key] = v
+	}
+
+	return v
+}
+
+func (s *SyncState) RecordPush(file LocalizationFile, entry *SyncStateEntry) {
+	s.file(file).Push = entry
+}
+
+func (s *SyncState) RecordPull(file LocalizationFile, entry *SyncStateEntry) {
+	s.file(file).Pull = entry
+}
+
+// LastPush returns the last push of the file to the given project and branch, or nil if there is none.
+func (s *SyncState) LastPush(file LocalizationFile, projectId string, branch string) *SyncStateEntry {
+	v, ok := s.Files[stateKey(file)]
+	if !ok || v.LocaleCode != file.LocaleCode || v.Push == nil || v.Push.ProjectId != projectId || v.Push.Branch != branch {
+		return nil
+	}
+
+	return v.Push
+}
+
+// LastPull returns the last pull of the file from the given project and branch, or nil if there is none.
+func (s *SyncState) LastPull(file LocalizationFile, projectId string, branch string) *SyncStateEntry {
+	v, ok := s.Files[stateKey(file)]
+	if !ok || v.LocaleCode != file.LocaleCode || v.Pull == nil || v.Pull.ProjectId != projectId || v.Pull.Branch != branch {
+		return nil
+	}
+
+	return v.Pull
+}
+
+// LastSync returns the most recent push or pull of the file with the given project and branch, or nil if there is none.
+func (s *SyncState) LastSync(file LocalizationFile, projectId string, branch string) *SyncStateEntry {
+	push := s.LastPush(file, projectId, branch)
+	pull := s.LastPull(file, projectId, branch)
+
+	if push == nil || (pull != nil && pull.Timestamp.After(push.Timestamp)) {
+		return pull
+	}
+
+	return push
+}
+
+// IsModifiedLocally reports whether the content of the local file differs from its content at the last push or pull.
+// Files that were never synced are reported as modified.
+func (s *SyncState) IsModifiedLocally(file LocalizationFile, projectId string, branch string) (bool, error) {
+	last := s.LastSync(file, projectId, branch)
+	if last == nil {
+		return true, nil
+	}
+
+	hash, err := hashFile(file.File)
+	if errors.Is(err, os.ErrNotExist) {
+		return true, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return hash != last.Hash, nil
+}
+
+func hashContent(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func hashFile(path string) (string, error) {
+	b, err := os.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return "", err
+	}
+
+	return hashContent(b), nil
+}
@@ -0,0 +1,144 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestHashContent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"empty", "", "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"},
+		{"content", "{\"hello\": \"Hello\"}\n", "sha256:0c40c31d2e9795d175729d418568181be7ec75de544fab6b0d83748766927037"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hashContent([]byte(tt.data)); got != tt.want {
+				t.Errorf("hashContent() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSyncStateLastSync(t *testing.T) {
+	file := LocalizationFile{File: "lang/en.json", LocaleCode: "en"}
+	older := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(time.Hour)
+
+	entry := func(hash string, projectId string, branch string, timestamp time.Time) *SyncStateEntry {
+		return &SyncStateEntry{Hash: hash, Timestamp: timestamp, ProjectId: projectId, Branch: branch}
+	}
+
+	tests := []struct {
+		name      string
+		push      *SyncStateEntry
+		pull      *SyncStateEntry
+		projectId string
+		branch    string
+		lookup    LocalizationFile
+		wantHash  string
+	}{
+		{"never synced", nil, nil, "p1", "", file, ""},
+		{"only pushed", entry("push", "p1", "", older), nil, "p1", "", file, "push"},
+		{"only pulled", nil, entry("pull", "p1", "", older), "p1", "", file, "pull"},
+		{"pull after push", entry("push", "p1", "", older), entry("pull", "p1", "", newer), "p1", "", file, "pull"},
+		{"push after pull", entry("push", "p1", "", newer), entry("pull", "p1", "", older), "p1", "", file, "push"},
+		{"other project", entry("push", "p2", "", older), nil, "p1", "", file, ""},
+		{"other branch", entry("push", "p1", "main", older), nil, "p1", "feature", file, ""},
+		{"same branch", entry("push", "p1", "main", older), nil, "p1", "main", file, "push"},
+		{"only the push of the project", entry("push", "p1", "", older), entry("pull", "p2", "", newer), "p1", "", file, "push"},
+		{"other locale of the file", entry("push", "p1", "", older), nil, "p1", "", LocalizationFile{File: "lang/en.json", LocaleCode: "de"}, ""},
+		{"same file with another path", entry("push", "p1", "", older), nil, "p1", "", LocalizationFile{File: "./lang/../lang/en.json", LocaleCode: "en"}, "push"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := &SyncState{Version: stateVersion, Files: map[string]*SyncStateFile{}}
+			if tt.push != nil {
+				state.RecordPush(file, tt.push)
+			}
+			if tt.pull != nil {
+				state.RecordPull(file, tt.pull)
+			}
+
+			got := state.LastSync(tt.lookup, tt.projectId, tt.branch)
+			if tt.wantHash == "" {
+				if got != nil {
+					t.Errorf("LastSync() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Hash != tt.wantHash {
+				t.Errorf("LastSync() = %+v, want the %s entry", got, tt.wantHash)
+			}
+		})
+	}
+}
+
+func TestSyncStateIsModifiedLocally(t *testing.T) {
+	dir := t.TempDir()
+	synced := []byte("{\"hello\": \"Hello\"}\n")
+
+	tests := []struct {
+		name    string
+		content []byte
+		record  bool
+		want    bool
+	}{
+		{"unchanged", synced, true, false},
+		{"modified", []byte("{\"hello\": \"Hello!\"}\n"), true, true},
+		{"never synced", synced, false, true},
+		{"deleted", nil, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := LocalizationFile{File: filepath.Join(dir, tt.name+".json"), LocaleCode: "en"}
+			if tt.content != nil {
+				if err := os.WriteFile(file.File, tt.content, 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			state := &SyncState{Version: stateVersion, Files: map[string]*SyncStateFile{}}
+			if tt.record {
+				state.RecordPull(file, newSyncStateEntry(hashContent(synced), "p1", ""))
+			}
+
+			got, err := state.IsModifiedLocally(file, "p1", "")
+			if err != nil {
+				t.Fatalf("IsModifiedLocally() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsModifiedLocally() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,353 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/localizely/localizely-client-go"
+	"github.com/spf13/cobra"
+)
+
+const (
+	statusInSync          = "in_sync"
+	statusModifiedLocally = "modified_locally"
+	statusChangedRemotely = "changed_remotely"
+	statusBothModified    = "both_modified"
+	statusNotSynced       = "not_synced"
+	statusMissingLocally  = "missing_locally"
+	statusError           = "error"
+)
+
+var statusLabels = map[string]string{
+	statusInSync:          "in sync",
+	statusModifiedLocally: "modified locally",
+	statusChangedRemotely: "changed remotely",
+	statusBothModified:    "both modified",
+	statusNotSynced:       "not synced",
+	statusMissingLocally:  "missing locally",
+	statusError:           "error",
+}
+
+var statusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show the sync status of localization files",
+	Long:  "Show the sync status of localization files\n\nEvery configured upload and download file is compared with its state at the last push or pull (see the .localizely/state.json file) and with its current version in Localizely, and reported as:\n  in sync           the local file matches Localizely\n  modified locally  the local file changed since the last sync\n  changed remotely  the file changed in Localizely since the last sync\n  both modified     both the local file and the file in Localizely changed\n  not synced        the file differs from Localizely and was never pushed or pulled\n  missing locally   the local file does not exist\n  error             the status could not be checked, and the command exits with a non-zero status\n\nWith the local flag, only local changes since the last sync are checked, without calling the Localizely API, so files with 'locales: all' and the all-languages flag are not supported.",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindDownloadFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		local, err := cmd.Flags().GetBool("local")
+		checkError(err)
+
+		var projects []ProjectStatus
+		err = forEachProject(func(project string) error {
+			pullConfig, uploadFiles, err := readStatusConfig(cmd.Context(), local)
+			if err != nil {
+				return err
+			}
+
+			// The statuses of the files that failed are reported too, before the error
+			statuses, err := getFileStatuses(cmd.Context(), pullConfig, uploadFiles, local)
+			if statuses != nil {
+				projects = append(projects, ProjectStatus{
+					Project:   project,
+					ProjectId: pullConfig.ProjectId,
+					Branch:    pullConfig.Branch,
+					Files:     statuses,
+				})
+			}
+
+			return err
+		})
+
+		total := 0
+		for i, v := range projects {
+			total += len(v.Files)
+
+			if isStructuredOutput() {
+				for _, f := range v.Files {
+					addFileReport(newFileStatusReport(v.Project, f))
+				}
+				continue
+			}
+
+			if i > 0 {
+				fmt.Println()
+			}
+			printFileStatuses(v)
+		}
+		if err != nil && !isStructuredOutput() {
+			fmt.Fprintln(os.Stderr)
+		}
+		checkError(err)
+
+		if isStructuredOutput() {
+			reportSuccess("Checked the status of %d localization files", total)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(statusCmd)
+
+	addDownloadFlags(statusCmd)
+	statusCmd.Flags().Bool("local", false, "Check only local changes since the last sync, without calling the Localizely API")
+}
+
+type ProjectStatus struct {
+	Project   string
+	ProjectId string
+	Branch    string
+	Files     []FileStatus
+}
+
+type FileStatus struct {
+	File       string
+	LocaleCode string
+	Upload     bool
+	Download   bool
+	Status     string
+	Err        error
+	LastPush   *SyncStateEntry
+	LastPull   *SyncStateEntry
+}
+
+// newFileStatusReport reports the sync status of the file, together with its last push and pull.
+func newFileStatusReport(project string, status FileStatus) FileReport {
+	report := FileReport{
+		Project:    project,
+		File:       status.File,
+		LocaleCode: status.LocaleCode,
+		Status:     status.Status,
+		Upload:     status.Upload,
+		Download:   status.Download,
+		LastPush:   status.LastPush,
+		LastPull:   status.LastPull,
+	}
+	if status.Err != nil {
+		report.Error = newErrorReport(status.Err)
+	}
+
+	return report
+}
+
+// readStatusConfig reads the pull config and the upload files, and validates only what is needed for the status check.
+func readStatusConfig(ctx context.Context, local bool) (PullConfig, []LocalizationFile, error) {
+	pullConfig, err := readPullConfig(ctx, local)
+	if err != nil {
+		return pullConfig, nil, err
+	}
+
+	pushConfig, err := readPushConfig(ctx, local)
+	if err != nil {
+		return pullConfig, nil, err
+	}
+
+	// Upload files are compared with their download from Localizely, so they use the same download params
+	uploadFiles := withDefaultFileParams(pushConfig.Files, pullConfig.FileType, pullConfig.JavaPropertiesEncoding)
+
+	if !local {
+		if err := validateApiToken(pullConfig.ApiToken); err != nil {
+			return pullConfig, nil, err
+		}
+
+		if err := validateFileParams(append(uploadFiles, pullConfig.Files...)); err != nil {
+			return pullConfig, nil, err
+		}
+	}
+
+	if err := validateProjectId(pullConfig.ProjectId); err != nil {
+		return pullConfig, nil, err
+	}
+
+	if err := validateConcurrency(pullConfig.Concurrency); err != nil {
+		return pullConfig, nil, err
+	}
+
+	return pullConfig, uploadFiles, nil
+}
+
+// getFileStatuses returns the status of every upload and download file, in the order of the configuration (upload files first).
+func getFileStatuses(ctx context.Context, config PullConfig, uploadFiles []LocalizationFile, local bool) ([]FileStatus, error) {
+	state, err := readSyncState()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Failed to read the '%s' file\nError: %v\n", formatStateJsonFilePath(), err))
+	}
+
+	var files []LocalizationFile
+	var statuses []FileStatus
+	index := map[string]int{}
+
+	add := func(v LocalizationFile, upload bool) {
+		key := stateKey(v) + "|" + v.LocaleCode
+		i, ok := index[key]
+		if !ok {
+			i = len(statuses)
+			index[key] = i
+			files = append(files, v)
+			statuses = append(statuses, FileStatus{File: filepath.Clean(v.File), LocaleCode: v.LocaleCode})
+		}
+		if upload {
+			statuses[i].Upload = true
+		} else {
+			statuses[i].Download = true
+		}
+	}
+	for _, v := range uploadFiles {
+		add(v, true)
+	}
+	for _, v := range config.Files {
+		add(v, false)
+	}
+
+	apiClient := config.ApiClient
+	ctx = newApiContext(ctx, config.ApiToken)
+
+	results := runFileTasks(files, config.Concurrency, func(i int, v LocalizationFile) error {
+		status := &statuses[i]
+		status.LastPush = state.LastPush(v, config.ProjectId, config.Branch)
+		status.LastPull = state.LastPull(v, config.ProjectId, config.Branch)
+
+		var err error
+		status.Status, err = getFileStatus(ctx, apiClient, config, state, v, local)
+		if err != nil {
+			status.Status = statusError
+			status.Err = err
+		}
+
+		return err
+	})
+
+	return statuses, joinFileErrors(results)
+}
+
+func getFileStatus(ctx context.Context, apiClient *localizely.APIClient, config PullConfig, state *SyncState, file LocalizationFile, local bool) (string, error) {
+	localContent, err := os.ReadFile(filepath.Clean(file.File))
+	if errors.Is(err, os.ErrNotExist) {
+		return statusMissingLocally, nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	last := state.LastSync(file, config.ProjectId, config.Branch)
+	localModified := last == nil || hashContent(localContent) != last.Hash
+
+	if local {
+		switch {
+		case last == nil:
+			return statusNotSynced, nil
+		case localModified:
+			return statusModifiedLocally, nil
+		}
+		return statusInSync, nil
+	}
+
+	remoteContent, _, err := downloadLocalizationFile(ctx, apiClient, config, file)
+	if err != nil {
+		return "", err
+	}
+
+	if isSameContent(file.FileType, localContent, remoteContent) {
+		return statusInSync, nil
+	}
+
+	if last == nil {
+		return statusNotSynced, nil
+	}
+
+	var remoteChanged bool
+	if lastPull := state.LastPull(file, config.ProjectId, config.Branch); lastPull == last {
+		// A pull saves the remote content as is, so its hash is the remote baseline
+		remoteChanged = hashContent(remoteContent) != lastPull.Hash
+	} else {
+		// After a push, the remote content is not known byte for byte, but if the local file is unchanged the difference must come from Localizely
+		remoteChanged = !localModified
+	}
+
+	switch {
+	case localModified && remoteChanged:
+		return statusBothModified, nil
+	case localModified:
+		return statusModifiedLocally, nil
+	}
+	return statusChangedRemotely, nil
+}
+
+// isSameContent reports whether the contents are equal, either byte for byte, or key by key for the file types that support parsing.
+func isSameContent(fileType string, a []byte, b []byte) bool {
+	if bytes.Equal(a, b) {
+		return true
+	}
+
+	if !supportsKeyParsing(fileType) {
+		return false
+	}
+
+	aKeys, err := parseLocalizationFile(fileType, a)
+	if err != nil {
+		return false
+	}
+
+	bKeys, err := parseLocalizationFile(fileType, b)
+	if err != nil {
+		return false
+	}
+
+	return maps.Equal(aKeys, bKeys)
+}
+
+func printFileStatuses(project ProjectStatus) {
+	if project.Project != "" {
+		fmt.Printf("Project: %s (%s)\n", project.Project, project.ProjectId)
+	} else {
+		fmt.Printf("Project: %s\n", project.ProjectId)
+	}
+	if project.Branch != "" {
+		fmt.Printf("Branch:  %s\n", project.Branch)
+	}
+	fmt.Println()
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, v := range project.Files {
+		label := statusLabels[v.Status]
+		switch v.Status {
+		case statusInSync:
+			label = color.GreenString(label)
+		case statusError, statusBothModified, statusMissingLocally:
+			label = color.RedString(label)
+		default:
+			label = color.YellowString(label)
+		}
+		fmt.Fprintf(w, "  %s\t%s (%s)\n", label, v.File, v.LocaleCode)
+	}
+	w.Flush()
+}
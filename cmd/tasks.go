@@ -0,0 +1,114 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+type FileResult struct {
+	File     LocalizationFile
+	Err      error
+	Skipped  bool
+	Duration time.Duration
+	// Bytes and HttpStatus are set by the tasks that transfer the file
+	Bytes      int64
+	HttpStatus int
+}
+
+// runFileTasks runs the task for every file using at most concurrency goroutines.
+// Results are returned in the same order as the files, regardless of the order in which the tasks finish.
+func runFileTasks(files []LocalizationFile, concurrency int, task func(i int, file LocalizationFile) error) []FileResult {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]FileResult, len(files))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i, v := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+
+		go func(i int, v LocalizationFile) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			start := time.Now()
+			err := task(i, v)
+			results[i] = FileResult{File: v, Err: err, Duration: time.Since(start)}
+
+			var apiErr *ApiError
+			if errors.As(err, &apiErr) {
+				results[i].HttpStatus = apiErr.StatusCode
+			}
+		}(i, v)
+	}
+
+	wg.Wait()
+
+	return results
+}
+
+// joinFileErrors returns the errors of the failed files, as a partial failure if other files succeeded.
+func joinFileErrors(results []FileResult) error {
+	var errs []error
+	for _, v := range results {
+		if v.Err != nil {
+			errs = append(errs, v.Err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &FileErrors{
+		Message: fmt.Sprintf("%d of %d localization files failed\n\n%v", len(errs), len(results), errors.Join(errs...)),
+		Errs:    errs,
+		Partial: len(errs) < len(results),
+	}
+}
+
+func printFileResults(results []FileResult, action string) {
+	if len(results) == 0 {
+		return
+	}
+
+	fmt.Println()
+	for _, v := range results {
+		if v.Err != nil {
+			fmt.Printf("%s %s (%s)\n", color.RedString("%-8s", "Failed"), v.File.File, v.File.LocaleCode)
+		} else if v.Skipped {
+			fmt.Printf("%s %s (%s)\n", color.YellowString("%-8s", "Skipped"), v.File.File, v.File.LocaleCode)
+		} else {
+			fmt.Printf("%s %s (%s)\n", color.GreenString("%-8s", action), v.File.File, v.File.LocaleCode)
+		}
+	}
+	fmt.Println()
+}
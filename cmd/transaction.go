@@ -0,0 +1,221 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// fileTransaction stages file writes in temporary files next to their targets, and moves them into place only on commit.
+// If the commit fails halfway, the files that were already replaced are restored from backups.
+type fileTransaction struct {
+	mu          sync.Mutex
+	staged      []stagedFile
+	createdDirs []string
+}
+
+type stagedFile struct {
+	path       string
+	tmpPath    string
+	backupPath string
+}
+
+func newFileTransaction() *fileTransaction {
+	return &fileTransaction{}
+}
+
+// Stage writes the data to a temporary file in the directory of the target file. The target file is not touched.
+func (t *fileTransaction) Stage(path string, data []byte) error {
+	dir := filepath.Dir(path)
+
+	t.mu.Lock()
+	err := t.createDir(dir)
+	t.mu.Unlock()
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to create directory '%s'\nError: %v\n", dir, err))
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to save localization file '%s'\nError: %v\n", path, err))
+	}
+
+	t.mu.Lock()
+	t.staged = append(t.staged, stagedFile{path: path, tmpPath: tmp.Name()})
+	t.mu.Unlock()
+
+	_, err = tmp.Write(data)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), fileModeOrDefault(path, 0644))
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to save localization file '%s'\nError: %v\n", path, err))
+	}
+
+	return nil
+}
+
+// createDir creates the directory and remembers the directories that did not exist before, so they can be removed on rollback.
+func (t *fileTransaction) createDir(dir string) error {
+	var missing []string
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(d); err == nil || !errors.Is(err, os.ErrNotExist) {
+			break
+		}
+		missing = append(missing, d)
+		if filepath.Dir(d) == d {
+			break
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+
+	// Deepest directories first, so they can be removed in order
+	t.createdDirs = append(missing, t.createdDirs...)
+
+	return nil
+}
+
+// Commit moves the staged files into place. On failure, the previous state of all files is restored.
+// Each file is replaced with a single rename, so the target path always exists, even if the process is killed during the commit.
+func (t *fileTransaction) Commit() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for i := range t.staged {
+		v := &t.staged[i]
+
+		if _, err := os.Stat(v.path); err == nil {
+			if err := backupFile(v.path, v.tmpPath+".bak"); err != nil {
+				t.rollback()
+				return errors.New(fmt.Sprintf("Failed to replace localization file '%s'\nError: %v\nNo local files were changed\n", v.path, err))
+			}
+			v.backupPath = v.tmpPath + ".bak"
+		}
+
+		// The rename replaces an existing file atomically (MoveFileEx with MOVEFILE_REPLACE_EXISTING on Windows)
+		if err := os.Rename(v.tmpPath, v.path); err != nil {
+			t.rollback()
+			return errors.New(fmt.Sprintf("Failed to replace localization file '%s'\nError: %v\nNo local files were changed\n", v.path, err))
+		}
+		v.tmpPath = ""
+	}
+
+	for _, v := range t.staged {
+		if v.backupPath != "" {
+			os.Remove(v.backupPath)
+		}
+	}
+	t.staged = nil
+	t.createdDirs = nil
+
+	return nil
+}
+
+// Rollback removes the staged files and the directories created for them.
+func (t *fileTransaction) Rollback() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.rollback()
+}
+
+func (t *fileTransaction) rollback() {
+	for i := len(t.staged) - 1; i >= 0; i-- {
+		v := t.staged[i]
+
+		switch {
+		case v.tmpPath != "":
+			// Not moved into place, so the target file is untouched
+			os.Remove(v.tmpPath)
+			if v.backupPath != "" {
+				os.Remove(v.backupPath)
+			}
+		case v.backupPath != "":
+			os.Rename(v.backupPath, v.path)
+		default:
+			// The file did not exist before
+			os.Remove(v.path)
+		}
+	}
+
+	for _, dir := range t.createdDirs {
+		os.Remove(dir)
+	}
+
+	t.staged = nil
+	t.createdDirs = nil
+}
+
+// backupFile keeps the current content of the file at the backup path, without moving the file itself.
+// A hard link is used where the file system supports it, and a copy otherwise.
+func backupFile(path string, backupPath string) error {
+	if err := os.Link(path, backupPath); err == nil {
+		return nil
+	}
+
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(backupPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, fileModeOrDefault(path, 0644))
+	if err != nil {
+		return err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(backupPath)
+	}
+
+	return err
+}
+
+func fileModeOrDefault(path string, mode os.FileMode) os.FileMode {
+	if info, err := os.Stat(path); err == nil {
+		return info.Mode().Perm()
+	}
+
+	return mode
+}
@@ -0,0 +1,188 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileTransactionCommit(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "en.json")
+	created := filepath.Join(dir, "lang", "de", "de.json")
+	writeTestFile(t, existing, "old")
+	if err := os.Chmod(existing, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := newFileTransaction()
+	stageTestFile(t, tx, existing, "new")
+	stageTestFile(t, tx, created, "neu")
+
+	// Staging does not touch the target files
+	assertTestFile(t, existing, "old")
+	assertNotExist(t, created)
+
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("Commit() error = %v", err)
+	}
+
+	assertTestFile(t, existing, "new")
+	assertTestFile(t, created, "neu")
+
+	info, err := os.Stat(existing)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("mode of the replaced file = %v, want %v", info.Mode().Perm(), os.FileMode(0600))
+	}
+	assertDirEntries(t, dir, "en.json", "lang")
+	assertDirEntries(t, filepath.Dir(created), "de.json")
+}
+
+func TestFileTransactionRollback(t *testing.T) {
+	dir := t.TempDir()
+	existing := filepath.Join(dir, "en.json")
+	created := filepath.Join(dir, "lang", "de", "de.json")
+	writeTestFile(t, existing, "old")
+
+	tx := newFileTransaction()
+	stageTestFile(t, tx, existing, "new")
+	stageTestFile(t, tx, created, "neu")
+	tx.Rollback()
+
+	assertTestFile(t, existing, "old")
+	// The temporary files and the created directories are removed
+	assertDirEntries(t, dir, "en.json")
+}
+
+func TestFileTransactionCommitFailureRestoresFiles(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "en.json")
+	second := filepath.Join(dir, "de.json")
+	created := filepath.Join(dir, "fr.json")
+	writeTestFile(t, first, "old en")
+	writeTestFile(t, second, "old de")
+
+	tx := newFileTransaction()
+	stageTestFile(t, tx, first, "new en")
+	stageTestFile(t, tx, created, "new fr")
+	stageTestFile(t, tx, second, "new de")
+
+	// The staged file of the last target disappears, so the commit fails after the other files were replaced
+	if err := os.Remove(tx.staged[2].tmpPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Commit() error = nil, want an error")
+	}
+
+	assertTestFile(t, first, "old en")
+	assertTestFile(t, second, "old de")
+	assertNotExist(t, created)
+	assertDirEntries(t, dir, "de.json", "en.json")
+}
+
+func TestBackupFileKeepsTarget(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "en.json")
+	backupPath := filepath.Join(dir, ".en.json.bak")
+	writeTestFile(t, path, "old")
+
+	if err := backupFile(path, backupPath); err != nil {
+		t.Fatalf("backupFile() error = %v", err)
+	}
+
+	// The target is never moved away, so it exists until the staged file replaces it
+	assertTestFile(t, path, "old")
+	assertTestFile(t, backupPath, "old")
+
+	// Replacing the target does not change the backup
+	writeTestFile(t, filepath.Join(dir, "new.tmp"), "new")
+	if err := os.Rename(filepath.Join(dir, "new.tmp"), path); err != nil {
+		t.Fatal(err)
+	}
+	assertTestFile(t, path, "new")
+	assertTestFile(t, backupPath, "old")
+}
+
+func writeTestFile(t *testing.T, path string, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func stageTestFile(t *testing.T, tx *fileTransaction, path string, content string) {
+	t.Helper()
+
+	if err := tx.Stage(path, []byte(content)); err != nil {
+		t.Fatalf("Stage(%s) error = %v", path, err)
+	}
+}
+
+func assertTestFile(t *testing.T, path string, want string) {
+	t.Helper()
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read '%s': %v", path, err)
+	}
+	if string(b) != want {
+		t.Errorf("content of '%s' = %q, want %q", path, b, want)
+	}
+}
+
+func assertNotExist(t *testing.T, path string) {
+	t.Helper()
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("'%s' exists, want it not to exist", path)
+	}
+}
+
+func assertDirEntries(t *testing.T, dir string, want ...string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, v := range entries {
+		got = append(got, v.Name())
+	}
+	if len(got) != len(want) {
+		t.Fatalf("entries of '%s' = %v, want %v", dir, got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Fatalf("entries of '%s' = %v, want %v", dir, got, want)
+		}
+	}
+}
@@ -24,6 +24,7 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"strings"
 
@@ -62,44 +63,49 @@ var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "Update Localizely CLI to the latest version",
 	Run: func(cmd *cobra.Command, args []string) {
+		restoreDefaultSignals()
+
 		currVersion := semver.MustParse(Version)
 
+		// The update library creates its own clients (e.g. for the asset download, or with a GITHUB_TOKEN), which all use the default transport,
+		// so it is replaced to send every request through the same proxy and TLS settings
+		httpClient, err := newHttpClient()
+		checkError(err)
+		http.DefaultTransport = httpClient.Transport
+
 		latest, found, err := selfupdate.DetectLatest("localizely/localizely-cli")
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to detect the latest release\nError: %v\n", err)
-			os.Exit(1)
+			checkError(newApiError(fmt.Sprintf("Failed to detect the latest release\nError: %v\n", err), nil, err))
 		}
 
 		if !found || latest.Version.LTE(currVersion) {
-			color.Green("Localizely CLI is up to date")
+			reportSuccess("Localizely CLI is up to date")
 			return
 		}
 
-		fmt.Printf("Current version: %s\n", currVersion)
-		fmt.Printf("Latest version:  %s\n\n", latest.Version)
+		fmt.Fprintf(textOutput(), "Current version: %s\n", currVersion)
+		fmt.Fprintf(textOutput(), "Latest version:  %s\n\n", latest.Version)
 
 		var confirm bool
 		err = scanConfirmUpdate(&confirm)
 		checkError(err)
 
 		if !confirm {
-			fmt.Println("Update canceled")
+			reportWarning("Update canceled")
 			return
 		}
 
 		exe, err := os.Executable()
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to locate executable path\nError: %v\n", err)
-			os.Exit(1)
+			checkError(errors.New(fmt.Sprintf("Failed to locate executable path\nError: %v\n", err)))
 		}
 
 		err = selfupdate.UpdateTo(latest.AssetURL, exe)
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Failed to update\nError: %v\n", err)
-			os.Exit(1)
+			checkError(errors.New(fmt.Sprintf("Failed to update\nError: %v\n", err)))
 		}
 
-		color.Green("Successfully updated to %s", latest.Version)
+		reportSuccess("Successfully updated to %s", latest.Version)
 	},
 }
 
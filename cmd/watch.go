@@ -0,0 +1,196 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/cobra"
+)
+
+var watchCmd = &cobra.Command{
+	Use:     "watch",
+	Short:   "Watch upload files and push them to Localizely when they change",
+	Long:    "Watch upload files and push them to Localizely when they change\n\nOnly the changed file is pushed, after no further changes are detected for the debounce duration. Files whose content has not changed since their last push are skipped.\nFailed pushes are reported and the watching continues, so the next change of the file is pushed again.",
+	Example: "  localizely-cli watch --debounce 1s",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindUploadFlags(cmd)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		configs, err := readPushConfigs(cmd.Context())
+		checkError(err)
+
+		if len(configs) > 1 {
+			checkError(newConfigError(fmt.Sprintf("The watch command works with a single project, but %d projects are configured.\n\nPlease select one using the project flag.\n\nUse \"localizely-cli [command] --help\" for more information about a command.\n\n", len(configs))))
+		}
+		config := configs[0]
+
+		debounce, err := cmd.Flags().GetDuration("debounce")
+		checkError(err)
+
+		err = watchLocalizationFiles(cmd.Context(), config, debounce)
+		checkError(err)
+
+		if isStructuredOutput() {
+			reportSuccess("Stopped watching")
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(watchCmd)
+
+	addUploadFlags(watchCmd)
+	watchCmd.Flags().Duration("debounce", 500*time.Millisecond, "Time to wait for further changes of a file before pushing it")
+}
+
+// watchLocalizationFiles pushes each upload file when it changes, until interrupted.
+// Directories are watched instead of files, so changes are also detected when editors save files by renaming a temporary file.
+func watchLocalizationFiles(ctx context.Context, config PushConfig, debounce time.Duration) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return errors.New(fmt.Sprintf("Failed to start watching files\nError: %v\n", err))
+	}
+	defer watcher.Close()
+
+	files := map[string]LocalizationFile{}
+	dirs := map[string]bool{}
+	for _, v := range config.Files {
+		path, err := filepath.Abs(filepath.Clean(v.File))
+		if err != nil {
+			return errors.New(fmt.Sprintf("Failed to resolve path of file '%s'\nError: %v\n", v.File, err))
+		}
+		files[path] = v
+
+		dir := filepath.Dir(path)
+		if !dirs[dir] {
+			if err := watcher.Add(dir); err != nil {
+				return errors.New(fmt.Sprintf("Failed to watch directory '%s'\nError: %v\n", dir, err))
+			}
+			dirs[dir] = true
+		}
+	}
+
+	// Files are pushed one at a time, in the order their changes settle
+	queue := make(chan string, len(files))
+	var mu sync.Mutex
+	timers := map[string]*time.Timer{}
+	queued := map[string]bool{}
+
+	schedule := func(path string) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if t, ok := timers[path]; ok {
+			t.Stop()
+		}
+		timers[path] = time.AfterFunc(debounce, func() {
+			mu.Lock()
+			defer mu.Unlock()
+
+			delete(timers, path)
+			if !queued[path] {
+				queued[path] = true
+				queue <- path
+			}
+		})
+	}
+
+	fmt.Fprintf(textOutput(), "Watching %d localization files for changes (press Ctrl-C to stop)\n", len(files))
+
+	for {
+		select {
+		case <-ctx.Done():
+			fmt.Fprintln(textOutput(), "\nStopped watching")
+			return nil
+
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) && !event.Has(fsnotify.Rename) {
+				continue
+			}
+			if _, ok := files[event.Name]; ok {
+				schedule(event.Name)
+			}
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
+
+		case path := <-queue:
+			mu.Lock()
+			delete(queued, path)
+			mu.Unlock()
+
+			pushWatchedFile(ctx, config, files[path])
+		}
+	}
+}
+
+func pushWatchedFile(ctx context.Context, config PushConfig, file LocalizationFile) {
+	// A rename-based save can report the change before the new file is in place
+	if _, err := os.Stat(filepath.Clean(file.File)); errors.Is(err, os.ErrNotExist) {
+		return
+	}
+
+	config.Files = []LocalizationFile{file}
+	config.SkipUnchanged = true
+
+	timestamp := time.Now().Format("15:04:05")
+
+	results, err := pushLocalizationFiles(ctx, config)
+	if isStructuredOutput() {
+		for _, v := range results {
+			if !v.Skipped {
+				addFileReport(newFileReport(config.Project, v, "pushed"))
+			}
+		}
+		if err != nil {
+			fmt.Fprint(os.Stderr, err)
+		}
+		return
+	}
+
+	if err != nil {
+		color.Red("[%s] Failed to push %s (%s)", timestamp, filepath.Clean(file.File), file.LocaleCode)
+		fmt.Fprint(os.Stderr, err)
+		return
+	}
+
+	if len(results) == 1 && results[0].Skipped {
+		return
+	}
+
+	color.Green("[%s] Pushed %s (%s)", timestamp, filepath.Clean(file.File), file.LocaleCode)
+}
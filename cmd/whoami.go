@@ -0,0 +1,154 @@
+/*
+Copyright © 2022 Localizely
+
+Permission is hereby granted, free of charge, to any person obtaining a copy
+of this software and associated documentation files (the "Software"), to deal
+in the Software without restriction, including without limitation the rights
+to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+copies of the Software, and to permit persons to whom the Software is
+furnished to do so, subject to the following conditions:
+
+The above copyright notice and this permission notice shall be included in
+all copies or substantial portions of the Software.
+
+THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+THE SOFTWARE.
+*/
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"text/tabwriter"
+
+	"github.com/localizely/localizely-client-go"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var whoamiCmd = &cobra.Command{
+	Use:     "whoami",
+	Short:   "Show which API token is used and verify it",
+	Long:    "Show which API token is used and verify it\n\nPrints the active profile, the redacted API token and where it comes from (flag, environment variable, config file, token file, token command or credentials file).\nIf a project ID is configured, the token is verified against the Localizely API by checking that it has access to the project.",
+	Example: "  localizely-cli whoami --profile acme",
+	PreRun: func(cmd *cobra.Command, args []string) {
+		bindConfigFlags(cmd, whoamiConfigFlags)
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkConfigFile()
+		checkError(err)
+
+		apiToken, _, err := readApiToken()
+		checkError(err)
+
+		err = validateApiToken(apiToken)
+		checkError(err)
+
+		var root *yaml.Node
+		if path := viper.ConfigFileUsed(); path != "" {
+			root, err = readConfigFileNode(path)
+			checkError(err)
+		}
+
+		profile := viper.GetString("profile")
+		if profile == "" {
+			profile = "default"
+		}
+		projectId := viper.GetString("project_id")
+
+		token := &TokenReport{
+			Profile:   profile,
+			ApiToken:  redactApiToken(apiToken),
+			Source:    configSource(cmd, whoamiConfigFlags, "", root, "api_token"),
+			ProjectId: projectId,
+		}
+
+		if isStructuredOutput() {
+			commandReport.Token = token
+		} else {
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "Profile:\t%s\n", token.Profile)
+			fmt.Fprintf(w, "API token:\t%s\n", token.ApiToken)
+			fmt.Fprintf(w, "Source:\t%s\n", token.Source)
+			fmt.Fprintf(w, "Project ID:\t%s\n", formatPlanValue(token.ProjectId))
+			w.Flush()
+			fmt.Println()
+		}
+
+		if projectId == "" {
+			reportWarning("The API token was not verified, set the project ID to verify it against the Localizely API")
+			return
+		}
+
+		retryPolicy := readRetryPolicy()
+		checkError(validateRetryPolicy(retryPolicy))
+
+		apiClient, err := newApiClient()
+		checkError(err)
+
+		err = verifyApiToken(newApiContext(cmd.Context(), apiToken), apiClient, projectId, retryPolicy)
+		checkError(err)
+		token.Verified = true
+
+		reportSuccess("The API token is valid and has access to the project")
+	},
+}
+
+// TokenReport describes the API token used by the CLI, with the token itself redacted.
+type TokenReport struct {
+	Profile   string `json:"profile"`
+	ApiToken  string `json:"api_token"`
+	Source    string `json:"source"`
+	ProjectId string `json:"project_id,omitempty"`
+	// Verified is set if the token was verified against the Localizely API
+	Verified bool `json:"verified"`
+}
+
+var whoamiConfigFlags = []ConfigFlag{
+	{"profile", "profile"},
+	{"api_token", "api-token"},
+	{"api_token_file", "api-token-file"},
+	{"project_id", "project-id"},
+	{"max_retries", "max-retries"},
+	{"retry_timeout", "retry-timeout"},
+}
+
+func init() {
+	rootCmd.AddCommand(whoamiCmd)
+
+	whoamiCmd.Flags().String("api-token", "", "API token\nYour API token from https://app.localizely.com/account")
+	whoamiCmd.Flags().String("api-token-file", "", "Path to a file containing the API token\nUsed if the API token is not set otherwise")
+	whoamiCmd.Flags().String("project-id", "", "Project ID\nProject used to verify the API token")
+	addRetryFlags(whoamiCmd)
+}
+
+// verifyApiToken checks that the api token has access to the project, as there is no API endpoint to verify a token on its own.
+func verifyApiToken(ctx context.Context, apiClient *localizely.APIClient, projectId string, retryPolicy RetryPolicy) error {
+	resp, err := executeWithRetry(ctx, retryPolicy, true, func() (*http.Response, error) {
+		_, resp, err := apiClient.TranslationStatusAPIAPI.GetTranslationStatus(ctx, projectId).Execute()
+		return resp, err
+	})
+	if err == nil {
+		resp.Body.Close()
+		return nil
+	}
+
+	if resp != nil {
+		switch resp.StatusCode {
+		case http.StatusUnauthorized:
+			return newApiError("The API token is not valid.\n\nTo create a new API token, please visit https://app.localizely.com/account.\n\n", resp, err)
+		case http.StatusForbidden, http.StatusNotFound:
+			return newApiError(fmt.Sprintf("The API token has no access to the project '%s', or the project does not exist.\n\nTo find your project ID, please visit https://app.localizely.com/projects\n\n", projectId), resp, err)
+		}
+	}
+
+	return newApiError(fmt.Sprintf("Failed to verify the API token\nError: %v\n%s\n", err, readResponseBody(resp)), resp, err)
+}